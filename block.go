@@ -3,6 +3,7 @@ package sequencefile
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 )
 
@@ -43,13 +44,37 @@ func (b *blockReader) next() bool {
 	return true
 }
 
+// validate checks that the key and value lengths add up to the size of the
+// decompressed sections, so that next can't read out of bounds.
+func (b *blockReader) validate() error {
+	if sumLengths(b.keyLengths) != len(b.keys) {
+		return errors.New("sequencefile: key lengths don't match the size of the keys section")
+	} else if sumLengths(b.valueLengths) != len(b.values) {
+		return errors.New("sequencefile: value lengths don't match the size of the values section")
+	}
+
+	return nil
+}
+
+func sumLengths(lengths []int) int {
+	sum := 0
+	for _, l := range lengths {
+		sum += l
+	}
+
+	return sum
+}
+
 func (r *Reader) scanBlock() bool {
 	for !r.block.next() {
+		if r.closed {
+			return false
+		}
+
 		err := r.startBlock()
 		if err == io.EOF {
 			return false
-		} else if err != nil {
-			r.close(err)
+		} else if err != nil && !r.recover(err) {
 			return false
 		}
 	}
@@ -60,22 +85,40 @@ func (r *Reader) scanBlock() bool {
 }
 
 func (r *Reader) startBlock() error {
-	// The sync appears at the start of every block, but it still has the -1
-	// length prefix in front, just for funsies.
-	r.clear()
-	_, err := r.consume(4)
-	if err != nil {
-		return err
-	}
+	r.block = blockReader{}
+	if r.synced {
+		// We already consumed the sync marker while recovering from an error.
+		r.synced = false
+		r.beginRecord()
+		r.start -= 4 + SyncSize
+	} else {
+		// The sync appears at the start of every block, but it still has the -1
+		// length prefix in front, just for funsies.
+		r.beginRecord()
+		r.clear()
+		_, err := r.consume(4)
+		if err == io.EOF && r.in.pos == r.start {
+			return io.EOF
+		} else if err != nil {
+			return unexpectedEOF(err)
+		}
 
-	err = r.checkSync()
-	if err != nil {
-		return err
+		err = r.checkSync()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
 	}
 
-	n, err := ReadVInt(r.reader)
+	r.resyncFrom = r.in.pos
+	return unexpectedEOF(r.readBlock())
+}
+
+func (r *Reader) readBlock() error {
+	n, err := ReadVInt(&r.in)
 	if err != nil {
 		return err
+	} else if n < 0 {
+		return fmt.Errorf("sequencefile: invalid block record count: %d", n)
 	}
 
 	block := blockReader{n: int(n)}
@@ -111,20 +154,33 @@ func (r *Reader) startBlock() error {
 		return err
 	}
 
+	err = block.validate()
+	if err != nil {
+		return err
+	}
+
 	r.block = block
+	r.stats.Blocks++
 	return nil
 }
 
 func (r *Reader) consumeSection() ([]byte, error) {
-	length, err := ReadVInt(r.reader)
+	length, err := ReadVInt(&r.in)
 	if err != nil {
 		return nil, err
+	} else if length < 0 {
+		return nil, fmt.Errorf("sequencefile: invalid section length: %d", length)
 	}
 
 	return r.consumeCompressed(int(length))
 }
 
 func readLengths(b []byte, n int) ([]int, error) {
+	// Every length takes at least one byte.
+	if n > len(b) {
+		return nil, fmt.Errorf("sequencefile: invalid block record count: %d", n)
+	}
+
 	buf := bytes.NewBuffer(b)
 	res := make([]int, 0, n)
 
@@ -136,6 +192,10 @@ func readLengths(b []byte, n int) ([]int, error) {
 			return nil, err
 		}
 
+		if vint < 0 {
+			return nil, fmt.Errorf("sequencefile: invalid length in block: %d", vint)
+		}
+
 		res = append(res, int(vint))
 	}
 
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+func cat(args []string) error {
+	flags := flag.NewFlagSet("cat", flag.ExitOnError)
+	p := newPrinter(flags)
+	limit := flags.Int64("n", 0, "stop after printing `count` records (0 means no limit)")
+	offset := flags.Int64("offset", 0, "skip the first `count` records")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("cat: no files given")
+	}
+
+	var seen, printed int64
+	for _, path := range flags.Args() {
+		if *limit > 0 && printed >= *limit {
+			break
+		}
+
+		sf, err := sequencefile.Open(path)
+		if err != nil {
+			p.flush()
+			return fmt.Errorf("%s: %s", path, err)
+		}
+
+		p.start(os.Stdout, sf.Header)
+		for sf.Scan() {
+			seen++
+			if seen <= *offset {
+				continue
+			}
+
+			if err := p.print(sf.Key(), sf.Value()); err != nil {
+				sf.Close()
+				p.flush()
+				return fmt.Errorf("%s: %s", path, err)
+			}
+
+			printed++
+			if *limit > 0 && printed >= *limit {
+				break
+			}
+		}
+
+		sf.Close()
+		if sf.Err() != nil {
+			p.flush()
+			return fmt.Errorf("%s: %s", path, sf.Err())
+		}
+	}
+
+	return p.flush()
+}
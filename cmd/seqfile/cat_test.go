@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCat(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	writeLongs(t, a, 1, 2, 3)
+	writeLongs(t, b, 4, 5)
+
+	out, err := captureStdout(t, cat, a)
+	require.NoError(t, err)
+	assert.Equal(t, "1\t1\n2\t2\n3\t3\n", out)
+
+	out, err = captureStdout(t, cat, "-keys", a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, parseKeys(t, out))
+
+	out, err = captureStdout(t, cat, "-json", b)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"key\":4,\"value\":4}\n{\"key\":5,\"value\":5}\n", out)
+
+	out, err = captureStdout(t, cat, "-json", "-keys", b)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"key\":4}\n{\"key\":5}\n", out)
+}
+
+func TestCatLimit(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	writeLongs(t, a, 1, 2, 3)
+	writeLongs(t, b, 4, 5)
+
+	cases := []struct {
+		args     []string
+		expected []int64
+	}{
+		{[]string{"-n", "2"}, []int64{1, 2}},
+		{[]string{"-n", "4"}, []int64{1, 2, 3, 4}},
+		{[]string{"-offset", "2"}, []int64{3, 4, 5}},
+		{[]string{"-offset", "3"}, []int64{4, 5}},
+		{[]string{"-offset", "2", "-n", "2"}, []int64{3, 4}},
+		{[]string{"-offset", "5"}, nil},
+	}
+
+	for _, c := range cases {
+		args := append([]string{"-keys"}, c.args...)
+		out, err := captureStdout(t, cat, append(args, a, b)...)
+		require.NoError(t, err, "%v", c.args)
+		assert.Equal(t, c.expected, parseKeys(t, out), "%v", c.args)
+	}
+}
+
+func TestCatFailure(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	writeLongs(t, a, 1, 2, 3)
+
+	// The records from the first file are printed before the error.
+	out, err := captureStdout(t, cat, "-keys", a, filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, parseKeys(t, out))
+}
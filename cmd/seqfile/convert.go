@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+func convert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	output := newOutputFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return errors.New("convert: expected an input and an output file")
+	}
+
+	in, out := flags.Arg(0), flags.Arg(1)
+	src, err := sequencefile.Open(in)
+	if err != nil {
+		return fmt.Errorf("%s: %s", in, err)
+	}
+	defer src.Close()
+
+	cfg, err := output.config(src.Header)
+	if err != nil {
+		return err
+	}
+
+	o, err := createOutput(out)
+	if err != nil {
+		return err
+	}
+
+	cfg.Writer = o
+	err = sequencefile.Transcode(cfg, src)
+	if err != nil {
+		o.Abort()
+		return fmt.Errorf("%s: %s", in, err)
+	}
+
+	return o.Commit()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+func parseCompression(s string) (sequencefile.Compression, error) {
+	for _, c := range []sequencefile.Compression{
+		sequencefile.NoCompression,
+		sequencefile.RecordCompression,
+		sequencefile.BlockCompression,
+	} {
+		if s == c.String() {
+			return c, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown compression type %q (must be none, record or block)", s)
+}
+
+func parseCodec(s string) (sequencefile.CompressionCodec, error) {
+	for _, c := range []sequencefile.CompressionCodec{
+		sequencefile.GzipCompression,
+		sequencefile.SnappyCompression,
+		sequencefile.ZlibCompression,
+		sequencefile.ZstdCompression,
+		sequencefile.Bzip2Compression,
+	} {
+		if s == c.String() {
+			return c, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown codec %q (must be gzip, snappy, zlib, zstd or bzip2)", s)
+}
+
+// metadataFlag is a flag.Value that collects repeated key=value pairs.
+type metadataFlag map[string]string
+
+func (m metadataFlag) String() string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (m metadataFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("metadata must be in the form key=value: %q", s)
+	}
+
+	m[k] = v
+	return nil
+}
+
+// outputFlags are the flags for commands that write a new SequenceFile based
+// on existing ones, which control how it differs from the input.
+type outputFlags struct {
+	name          string
+	compression   *string
+	codec         *string
+	blockSize     *int
+	clearMetadata *bool
+	metadata      metadataFlag
+}
+
+func newOutputFlags(flags *flag.FlagSet) *outputFlags {
+	o := &outputFlags{
+		name:          flags.Name(),
+		compression:   flags.String("compression", "", "compression `type` for the output: none, record or block (default: same as the input)"),
+		codec:         flags.String("codec", "", "compression `codec` for the output: gzip, snappy, zlib, zstd or bzip2 (default: same as the input)"),
+		blockSize:     flags.Int("block-size", 0, "target size in `bytes` of each block, for block compression"),
+		clearMetadata: flags.Bool("clear-metadata", false, "don't copy the metadata from the input"),
+		metadata:      metadataFlag{},
+	}
+
+	flags.Var(o.metadata, "meta", "add a `key=value` pair to the output metadata (can be repeated)")
+	return o
+}
+
+// config returns a WriterConfig for the output, based on the header of the
+// input.
+func (o *outputFlags) config(h sequencefile.Header) (*sequencefile.WriterConfig, error) {
+	cfg := &sequencefile.WriterConfig{
+		Compression:      h.Compression,
+		CompressionCodec: h.CompressionCodec,
+		BlockSize:        *o.blockSize,
+		Metadata:         map[string]string{},
+	}
+
+	var err error
+	if *o.compression != "" {
+		if cfg.Compression, err = parseCompression(*o.compression); err != nil {
+			return nil, err
+		}
+	}
+
+	if *o.codec != "" {
+		if cfg.CompressionCodec, err = parseCodec(*o.codec); err != nil {
+			return nil, err
+		}
+	} else if cfg.Compression != sequencefile.NoCompression && cfg.CompressionCodec == 0 {
+		return nil, fmt.Errorf("%s: -codec is required when compressing an uncompressed file", o.name)
+	}
+
+	if !*o.clearMetadata {
+		for k, v := range h.Metadata {
+			cfg.Metadata[k] = v
+		}
+	}
+	for k, v := range o.metadata {
+		cfg.Metadata[k] = v
+	}
+
+	return cfg, nil
+}
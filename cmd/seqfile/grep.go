@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/colinmarc/sequencefile/internal/pending"
+)
+
+// A matcher decides whether a record should be selected by grep, based on the
+// text form of its key and value, and for -from and -to, the serialized key.
+type matcher struct {
+	key, value     *regexp.Regexp
+	fixed          bool
+	keyFixed       string
+	valueFixed     string
+	prefix         string
+	hasFrom, hasTo bool
+	from, to       []byte
+	compare        sequencefile.RawComparator
+	invert         bool
+
+	needValue bool
+}
+
+// setRange sets the bounds for -from and -to, serialized as the key class so
+// that keys are compared in the same order as in Hadoop.
+func (m *matcher) setRange(keyClass, from, to string) error {
+	if !m.hasFrom && !m.hasTo {
+		return nil
+	}
+
+	var err error
+	if m.compare, err = sequencefile.NewRawComparator(keyClass); err != nil {
+		return fmt.Errorf("can't compare keys for -from and -to: %s", err)
+	}
+
+	if m.hasFrom {
+		if m.from, err = importValue(keyClass, tsvField(from)); err != nil {
+			return fmt.Errorf("invalid -from: %s", err)
+		}
+	}
+
+	if m.hasTo {
+		if m.to, err = importValue(keyClass, tsvField(to)); err != nil {
+			return fmt.Errorf("invalid -to: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *matcher) match(rawKey []byte, k, v interface{}) bool {
+	return m.matchAll(rawKey, k, v) != m.invert
+}
+
+func (m *matcher) matchAll(rawKey []byte, k, v interface{}) bool {
+	ks := fmt.Sprint(k)
+	if m.key != nil && !m.key.MatchString(ks) {
+		return false
+	} else if m.fixed && m.keyFixed != "" && ks != m.keyFixed {
+		return false
+	}
+
+	if m.prefix != "" && !strings.HasPrefix(ks, m.prefix) {
+		return false
+	}
+
+	if m.hasFrom && m.compare(rawKey, m.from) < 0 {
+		return false
+	} else if m.hasTo && m.compare(rawKey, m.to) >= 0 {
+		return false
+	}
+
+	if m.needValue {
+		vs := fmt.Sprint(v)
+		if m.value != nil && !m.value.MatchString(vs) {
+			return false
+		} else if m.fixed && m.valueFixed != "" && vs != m.valueFixed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func grep(args []string) error {
+	flags := flag.NewFlagSet("grep", flag.ExitOnError)
+	p := newPrinter(flags)
+	keyPattern := flags.String("key", "", "select records whose key matches the `regexp`")
+	valuePattern := flags.String("value", "", "select records whose value matches the `regexp`")
+	fixed := flags.Bool("F", false, "treat -key and -value as exact strings to match, rather than regexps")
+	prefix := flags.String("prefix", "", "select records whose key starts with `string`")
+	from := flags.String("from", "", "select records whose key is at least `key` (compared in the key class's order)")
+	to := flags.String("to", "", "select records whose key is less than `key` (compared in the key class's order)")
+	invert := flags.Bool("v", false, "select records that don't match")
+	limit := flags.Int64("n", 0, "stop after selecting `count` records (0 means no limit)")
+	out := flags.String("o", "", "write selected records to a new SequenceFile at `path`, instead of printing them")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("grep: no files given")
+	}
+
+	m := &matcher{
+		fixed:     *fixed,
+		prefix:    *prefix,
+		invert:    *invert,
+		needValue: *valuePattern != "",
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "from":
+			m.hasFrom = true
+		case "to":
+			m.hasTo = true
+		}
+	})
+
+	if *fixed {
+		m.keyFixed, m.valueFixed = *keyPattern, *valuePattern
+	} else {
+		var err error
+		if *keyPattern != "" {
+			if m.key, err = regexp.Compile(*keyPattern); err != nil {
+				return err
+			}
+		}
+		if *valuePattern != "" {
+			if m.value, err = regexp.Compile(*valuePattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	var o *pending.File
+	var w *sequencefile.Writer
+	var first sequencefile.Header
+	var selected int64
+
+files:
+	for i, path := range flags.Args() {
+		sf, err := sequencefile.Open(path)
+		if err != nil {
+			return abortGrep(o, fmt.Errorf("%s: %s", path, err))
+		}
+
+		if i == 0 {
+			first = sf.Header
+			if err := m.setRange(first.KeyClassName, *from, *to); err != nil {
+				sf.Close()
+				return err
+			}
+		} else if sf.Header.KeyClassName != first.KeyClassName || sf.Header.ValueClassName != first.ValueClassName {
+			sf.Close()
+			return abortGrep(o, fmt.Errorf("%s: key and value classes don't match %s", path, flags.Arg(0)))
+		}
+
+		if *out != "" && w == nil {
+			if o, err = createOutput(*out); err != nil {
+				sf.Close()
+				return err
+			}
+
+			w, err = sequencefile.NewRawWriter(&sequencefile.WriterConfig{
+				Writer:           o,
+				KeyClass:         first.KeyClassName,
+				ValueClass:       first.ValueClassName,
+				Compression:      first.Compression,
+				CompressionCodec: first.CompressionCodec,
+				Metadata:         first.Metadata,
+			})
+			if err != nil {
+				sf.Close()
+				return abortGrep(o, err)
+			}
+		} else if *out == "" {
+			p.start(os.Stdout, sf.Header)
+		}
+
+		keyRenderer := rendererFor(sf.Header.KeyClassName)
+		valueRenderer := rendererFor(sf.Header.ValueClassName)
+		for sf.Scan() {
+			k, err := render(keyRenderer, sf.Key())
+			if err != nil {
+				sf.Close()
+				return abortGrep(o, fmt.Errorf("%s: rendering key: %s", path, err))
+			}
+
+			var v interface{}
+			if m.needValue {
+				v, err = render(valueRenderer, sf.Value())
+				if err != nil {
+					sf.Close()
+					return abortGrep(o, fmt.Errorf("%s: rendering value: %s", path, err))
+				}
+			}
+
+			if !m.match(sf.Key(), k, v) {
+				continue
+			}
+
+			if w != nil {
+				err = w.AppendRaw(sf.Key(), sf.Value())
+			} else {
+				err = p.print(sf.Key(), sf.Value())
+			}
+			if err != nil {
+				sf.Close()
+				return abortGrep(o, fmt.Errorf("%s: %s", path, err))
+			}
+
+			selected++
+			if *limit > 0 && selected >= *limit {
+				sf.Close()
+				break files
+			}
+		}
+
+		sf.Close()
+		if sf.Err() != nil {
+			return abortGrep(o, fmt.Errorf("%s: %s", path, sf.Err()))
+		}
+	}
+
+	if w != nil {
+		if err := w.Close(); err != nil {
+			return abortGrep(o, err)
+		}
+
+		return o.Commit()
+	}
+
+	return p.flush()
+}
+
+func abortGrep(o *pending.File, err error) error {
+	if o != nil {
+		o.Abort()
+	}
+
+	return err
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	m := &matcher{key: regexp.MustCompile("^a")}
+	assert.True(t, m.match(nil, "abc", nil))
+	assert.False(t, m.match(nil, "bcd", nil))
+
+	m.invert = true
+	assert.False(t, m.match(nil, "abc", nil))
+	assert.True(t, m.match(nil, "bcd", nil))
+
+	m = &matcher{hasFrom: true, hasTo: true}
+	require.NoError(t, m.setRange(sequencefile.IntWritableClassName, "2", "10"))
+	for _, c := range []struct {
+		key      int32
+		expected bool
+	}{{1, false}, {2, true}, {9, true}, {10, false}} {
+		k := []byte{0, 0, 0, byte(c.key)}
+		assert.Equal(t, c.expected, m.match(k, c.key, nil), "%d", c.key)
+	}
+
+	m = &matcher{fixed: true, valueFixed: "foo", needValue: true, prefix: "k"}
+	assert.True(t, m.match(nil, "key", "foo"))
+	assert.False(t, m.match(nil, "key", "foobar"))
+	assert.False(t, m.match(nil, "nope", "foo"))
+}
+
+// grepValues runs grep with the given arguments, writing the selected records
+// to a new file, and returns the values of the records in it.
+func grepValues(t *testing.T, args ...string) []int64 {
+	out := filepath.Join(t.TempDir(), "out")
+	_, err := captureStdout(t, grep, append([]string{"-o", out}, args...)...)
+	require.NoError(t, err)
+
+	sf, err := sequencefile.Open(out)
+	require.NoError(t, err)
+	defer sf.Close()
+
+	values := []int64{}
+	for sf.Scan() {
+		values = append(values, sequencefile.LongWritable(sf.Value()))
+	}
+
+	require.NoError(t, sf.Err())
+	return values
+}
+
+func TestGrepRange(t *testing.T) {
+	dir := t.TempDir()
+
+	// Compared as strings, "-0.5" would sort before "-1".
+	doubles := filepath.Join(dir, "doubles")
+	writeKeys(t, doubles, sequencefile.DoubleWritableClassName, -1.5, -0.5, 0.25, 2.0, 10.0)
+	assert.Equal(t, []int64{1, 2}, grepValues(t, "-from", "-1", "-to", "2", doubles))
+	assert.Equal(t, []int64{3, 4}, grepValues(t, "-from", "1e0", doubles))
+	assert.Equal(t, []int64{0}, grepValues(t, "-to", "-1", doubles))
+
+	// BytesWritable keys are compared by their bytes, not their length.
+	bytes := filepath.Join(dir, "bytes")
+	writeKeys(t, bytes, sequencefile.BytesWritableClassName, []byte("a"), []byte("ab"), []byte("b"), []byte("ba"))
+	assert.Equal(t, []int64{1, 2}, grepValues(t, "-from", "ab", "-to", "ba", bytes))
+	assert.Equal(t, []int64{0, 3}, grepValues(t, "-from", "ab", "-to", "ba", "-v", bytes))
+
+	longs := filepath.Join(dir, "longs")
+	writeLongs(t, longs, -10, -2, 3, 20)
+	assert.Equal(t, []int64{-2, 3}, grepValues(t, "-from", "-5", "-to", "20", longs))
+
+	_, err := captureStdout(t, grep, "-from", "foo", longs)
+	assert.Error(t, err)
+}
+
+func TestGrepOutput(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in"), filepath.Join(dir, "out")
+	writeFile(t, in, sequencefile.WriterConfig{
+		Compression:      sequencefile.BlockCompression,
+		CompressionCodec: sequencefile.GzipCompression,
+		Metadata:         map[string]string{"foo": "bar"},
+	}, counting(100)...)
+
+	stdout, err := captureStdout(t, grep, "-o", out, "-key", "^[0-9]0$", "-n", "5", in)
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+
+	sf, err := sequencefile.Open(out)
+	require.NoError(t, err)
+	defer sf.Close()
+
+	assert.Equal(t, sequencefile.LongWritableClassName, sf.Header.KeyClassName)
+	assert.Equal(t, sequencefile.LongWritableClassName, sf.Header.ValueClassName)
+	assert.Equal(t, sequencefile.BlockCompression, sf.Header.Compression)
+	assert.Equal(t, sequencefile.GzipCompression, sf.Header.CompressionCodec)
+	assert.Equal(t, "bar", sf.Header.Metadata["foo"])
+
+	var keys, values []int64
+	for sf.Scan() {
+		keys = append(keys, sequencefile.LongWritable(sf.Key()))
+		values = append(values, sequencefile.LongWritable(sf.Value()))
+	}
+
+	require.NoError(t, sf.Err())
+	assert.Equal(t, []int64{10, 20, 30, 40, 50}, keys)
+	assert.Equal(t, keys, values)
+	assertNoOutput(t, dir, "in", "out")
+}
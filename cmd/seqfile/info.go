@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+func info(args []string) error {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	stats := flags.Bool("stats", false, "scan each file and print statistics about its contents")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("info: no files given")
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	for i, path := range flags.Args() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		if err := printInfo(w, path, *stats); err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return nil
+}
+
+func printInfo(w io.Writer, path string, withStats bool) error {
+	sf, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	h := sf.Header
+	fmt.Fprintf(w, "%s:\n", path)
+	fmt.Fprintf(w, "  version:           %d\n", h.Version)
+	fmt.Fprintf(w, "  key class:         %s\n", h.KeyClassName)
+	fmt.Fprintf(w, "  value class:       %s\n", h.ValueClassName)
+	fmt.Fprintf(w, "  compression:       %s\n", h.Compression)
+	if h.Compression != sequencefile.NoCompression {
+		fmt.Fprintf(w, "  codec:             %s\n", h.CompressionCodecClassName)
+	}
+	fmt.Fprintf(w, "  sync marker:       %s\n", hex.EncodeToString([]byte(h.SyncMarker)))
+
+	if len(h.Metadata) > 0 {
+		keys := make([]string, 0, len(h.Metadata))
+		for k := range h.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintf(w, "  metadata:\n")
+		for _, k := range keys {
+			fmt.Fprintf(w, "    %s: %s\n", k, h.Metadata[k])
+		}
+	}
+
+	if !withStats {
+		return nil
+	}
+
+	var records int64
+	var keySizes, valueSizes, blockSizes sizeStats
+	var blocks, blockRecords int64
+	for sf.Scan() {
+		if n := sf.Stats().Blocks; n != blocks {
+			if blocks > 0 {
+				blockSizes.add(blockRecords)
+			}
+
+			blocks = n
+			blockRecords = 0
+		}
+
+		records++
+		blockRecords++
+		keySizes.add(int64(len(sf.Key())))
+		valueSizes.add(int64(len(sf.Value())))
+	}
+
+	if sf.Err() != nil {
+		return sf.Err()
+	}
+
+	if blockRecords > 0 && blocks > 0 {
+		blockSizes.add(blockRecords)
+	}
+
+	stats := sf.Stats()
+	fmt.Fprintf(w, "  size:              %d\n", stats.Bytes)
+	fmt.Fprintf(w, "  records:           %d\n", records)
+	fmt.Fprintf(w, "  sync markers:      %d\n", stats.SyncMarkers)
+	if h.Compression == sequencefile.BlockCompression {
+		fmt.Fprintf(w, "  blocks:            %d\n", stats.Blocks)
+		fmt.Fprintf(w, "  records per block: %s\n", &blockSizes)
+	}
+
+	if h.Compression != sequencefile.NoCompression {
+		fmt.Fprintf(w, "  compressed:        %d\n", stats.CompressedBytes)
+		fmt.Fprintf(w, "  uncompressed:      %d\n", stats.UncompressedBytes)
+		if stats.CompressedBytes > 0 {
+			fmt.Fprintf(w, "  compression ratio: %.2f\n",
+				float64(stats.UncompressedBytes)/float64(stats.CompressedBytes))
+		}
+	}
+
+	fmt.Fprintf(w, "  key size:          %s\n", &keySizes)
+	fmt.Fprintf(w, "  value size:        %s\n", &valueSizes)
+	return nil
+}
+
+// sizeSampleSize is the number of sizes kept to estimate percentiles.
+const sizeSampleSize = 100000
+
+// sizeStats keeps the exact minimum, maximum, and mean of a series of sizes,
+// and estimates percentiles from a uniform sample of them.
+type sizeStats struct {
+	n, sum, min, max int64
+	sample           []int64
+	rand             *rand.Rand
+}
+
+func (s *sizeStats) add(size int64) {
+	if s.n == 0 || size < s.min {
+		s.min = size
+	}
+	if size > s.max {
+		s.max = size
+	}
+
+	s.n++
+	s.sum += size
+
+	// Reservoir sampling; see https://en.wikipedia.org/wiki/Reservoir_sampling.
+	if len(s.sample) < sizeSampleSize {
+		s.sample = append(s.sample, size)
+		return
+	}
+
+	if s.rand == nil {
+		s.rand = rand.New(rand.NewSource(1))
+	}
+	if i := s.rand.Int63n(s.n); i < sizeSampleSize {
+		s.sample[i] = size
+	}
+}
+
+func (s *sizeStats) percentile(p float64) int64 {
+	i := int(p*float64(len(s.sample)-1) + 0.5)
+	return s.sample[i]
+}
+
+func (s *sizeStats) String() string {
+	if s.n == 0 {
+		return "n/a"
+	}
+
+	sort.Slice(s.sample, func(i, j int) bool { return s.sample[i] < s.sample[j] })
+	return fmt.Sprintf("min %d, mean %.1f, p50 %d, p90 %d, p99 %d, max %d",
+		s.min, float64(s.sum)/float64(s.n),
+		s.percentile(0.5), s.percentile(0.9), s.percentile(0.99), s.max)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoStats(t *testing.T) {
+	dir := t.TempDir()
+	block, text := filepath.Join(dir, "block"), filepath.Join(dir, "text")
+	writeFile(t, block, sequencefile.WriterConfig{
+		Compression:      sequencefile.BlockCompression,
+		CompressionCodec: sequencefile.GzipCompression,
+		BlockSize:        400,
+	}, counting(100)...)
+	writeKeys(t, text, sequencefile.TextClassName, "a", "bb", "ccc", "dddd")
+
+	out, err := captureStdout(t, info, "-stats", block, text)
+	require.NoError(t, err)
+
+	// The files are separated by a blank line.
+	files := strings.SplitN(out, "\n\n", 2)
+	require.Len(t, files, 2)
+	blockInfo, textInfo := files[0], files[1]
+	assert.True(t, strings.HasPrefix(blockInfo, block+":\n"))
+	assert.True(t, strings.HasPrefix(textInfo, text+":\n"))
+
+	for _, line := range []string{
+		"  compression:       block\n",
+		"  records:           100\n",
+		"  blocks:            4\n",
+		"  records per block: min 25, mean 25.0, p50 25, p90 25, p99 25, max 25\n",
+		"  uncompressed:      1800\n",
+		"  key size:          min 8, mean 8.0, p50 8, p90 8, p99 8, max 8\n",
+		"  value size:        min 8, mean 8.0, p50 8, p90 8, p99 8, max 8\n",
+	} {
+		assert.Contains(t, blockInfo+"\n", line)
+	}
+
+	// The Text keys are serialized with a one-byte length.
+	for _, line := range []string{
+		"  compression:       none\n",
+		"  records:           4\n",
+		"  key size:          min 2, mean 3.5, p50 4, p90 5, p99 5, max 5\n",
+	} {
+		assert.Contains(t, textInfo, line)
+	}
+
+	assert.NotContains(t, textInfo, "blocks:")
+	assert.NotContains(t, textInfo, "compressed:")
+}
@@ -0,0 +1,72 @@
+// Command seqfile is a tool for inspecting and manipulating Hadoop
+// SequenceFiles.
+//
+// Usage:
+//
+//	seqfile <command> [flags] [arguments]
+//
+// Run 'seqfile help' for the list of commands.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"cat":     {"cat [-json] [-keys] [-n count] [-offset count] <files...>", cat},
+	"convert": {"convert [-compression type] [-codec codec] [-block-size bytes] [-meta key=value] <in> <out>", convert},
+	"export":  {"export <files...>", export},
+	"grep":    {"grep [-key regexp] [-value regexp] [-F] [-prefix string] [-from key] [-to key] [-v] [-n count] [-o out] <files...>", grep},
+	"head":    {"head [-n count] [-json] [-keys] <files...>", head},
+	"import":  {"import [-format json|tsv] [-key-class class] [-value-class class] [-compression type] [-codec codec] <out> [in]", importRecords},
+	"info":    {"info [-stats] <files...>", info},
+	"merge":   {"merge [-compression type] [-codec codec] [-block-size bytes] [-meta key=value] <out> <sorted files...>", merge},
+	"sample":  {"sample (-count count | -fraction fraction) [-seed seed] [-json] [-keys] <files...>", sample},
+	"tail":    {"tail [-n count] [-json] [-keys] <files...>", tail},
+	"text":    {"text <files...> (an alias for cat)", cat},
+	"verify":  {"verify [-q] <files...>", verify},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" {
+		printUsage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "seqfile: unknown command %q\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fatal(err)
+	}
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: seqfile <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "seqfile: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a SequenceFile to path with cfg, with the given
+// LongWritable keys, each with the same value, and returns its contents.
+func writeFile(t *testing.T, path string, cfg sequencefile.WriterConfig, keys ...int64) []byte {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	cfg.Writer = f
+	cfg.KeyClass = sequencefile.LongWritableClassName
+	cfg.ValueClass = sequencefile.LongWritableClassName
+	w, err := sequencefile.NewWriter(&cfg)
+	require.NoError(t, err)
+
+	for _, k := range keys {
+		require.NoError(t, w.Append(k, k))
+	}
+
+	require.NoError(t, w.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
+
+// writeLongs writes an uncompressed SequenceFile with the given LongWritable
+// keys to path, and returns its contents.
+func writeLongs(t *testing.T, path string, keys ...int64) []byte {
+	return writeFile(t, path, sequencefile.WriterConfig{}, keys...)
+}
+
+// writeKeys writes a SequenceFile with the given keys of keyClass to path,
+// with LongWritable values numbering the records.
+func writeKeys(t *testing.T, path, keyClass string, keys ...interface{}) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	w, err := sequencefile.NewWriter(&sequencefile.WriterConfig{
+		Writer:     f,
+		KeyClass:   keyClass,
+		ValueClass: sequencefile.LongWritableClassName,
+	})
+	require.NoError(t, err)
+
+	for i, k := range keys {
+		require.NoError(t, w.Append(k, int64(i)))
+	}
+
+	require.NoError(t, w.Close())
+}
+
+// counting returns the numbers from zero to n-1.
+func counting(n int) []int64 {
+	res := make([]int64, n)
+	for i := range res {
+		res[i] = int64(i)
+	}
+
+	return res
+}
+
+// captureStdout runs a command with the given arguments, and returns what it
+// printed to stdout.
+func captureStdout(t *testing.T, run func([]string) error, args ...string) (string, error) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	err = run(args)
+	w.Close()
+	return <-out, err
+}
+
+// parseKeys parses the output of a command run with -keys, for a file with
+// LongWritable keys.
+func parseKeys(t *testing.T, out string) []int64 {
+	var keys []int64
+	for _, line := range strings.Fields(out) {
+		k, err := strconv.ParseInt(line, 10, 64)
+		require.NoError(t, err)
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+func merge(args []string) error {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	output := newOutputFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		return errors.New("merge: expected an output file and at least one input")
+	}
+
+	out, paths := flags.Arg(0), flags.Args()[1:]
+	first, err := sequencefile.Open(paths[0])
+	if err != nil {
+		return fmt.Errorf("%s: %s", paths[0], err)
+	}
+	first.Close()
+
+	cfg, err := output.config(first.Header)
+	if err != nil {
+		return err
+	}
+
+	var src []io.Reader
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		src = append(src, bufio.NewReader(f))
+	}
+
+	o, err := createOutput(out)
+	if err != nil {
+		return err
+	}
+
+	cfg.Writer = o
+	err = sequencefile.Merge(src, cfg, nil)
+	if err != nil {
+		o.Abort()
+		return fmt.Errorf("merge: %s", err)
+	}
+
+	return o.Commit()
+}
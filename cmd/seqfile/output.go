@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/colinmarc/sequencefile/internal/pending"
+)
+
+// createOutput creates a file that is written under a temporary name, and
+// only renamed into place by Commit.
+func createOutput(path string) (*pending.File, error) {
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%d.tmp", filepath.Base(path), os.Getpid()))
+	return pending.Create(tmp, path)
+}
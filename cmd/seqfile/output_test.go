@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertNoOutput checks that dir contains only the given files, and no
+// temporary files.
+func assertNoOutput(t *testing.T, dir string, names ...string) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var found []string
+	for _, e := range entries {
+		found = append(found, e.Name())
+	}
+
+	assert.ElementsMatch(t, names, found)
+}
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in"), filepath.Join(dir, "out")
+	writeLongs(t, in, 1, 2, 3)
+
+	require.NoError(t, convert([]string{"-compression", "block", "-codec", "gzip", in, out}))
+
+	r, err := sequencefile.Open(out)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, sequencefile.BlockCompression, r.Header.Compression)
+	n := 0
+	for r.Scan() {
+		n++
+	}
+
+	require.NoError(t, r.Err())
+	assert.Equal(t, 3, n)
+}
+
+func TestConvertFailure(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in"), filepath.Join(dir, "out")
+	b := writeLongs(t, in, 1, 2, 3)
+	require.NoError(t, os.WriteFile(in, b[:len(b)-5], 0644))
+
+	assert.Error(t, convert([]string{in, out}))
+	assertNoOutput(t, dir, "in")
+}
+
+func TestMergeFailure(t *testing.T) {
+	dir := t.TempDir()
+	a, b, out := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "out")
+	writeLongs(t, a, 1, 2, 3)
+	writeLongs(t, b, 3, 2, 1)
+
+	assert.Error(t, merge([]string{out, a, b}))
+	assertNoOutput(t, dir, "a", "b")
+}
+
+func TestImportNullKeys(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.tsv"), filepath.Join(dir, "out")
+	require.NoError(t, os.WriteFile(in, []byte("(null)\tfoo\n\tbar\n"), 0644))
+
+	require.NoError(t, importRecords([]string{"-format", "tsv", "-key-class", "NullWritable", out, in}))
+
+	r, err := sequencefile.Open(out)
+	require.NoError(t, err)
+	defer r.Close()
+
+	var values []string
+	for r.Scan() {
+		assert.Empty(t, r.Key())
+		values = append(values, sequencefile.Text(r.Value()))
+	}
+
+	require.NoError(t, r.Err())
+	assert.Equal(t, []string{"foo", "bar"}, values)
+}
+
+func TestImportFailure(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.tsv"), filepath.Join(dir, "out")
+	require.NoError(t, os.WriteFile(in, []byte("1\tfoo\n2\tbar\nthree\tbaz\n"), 0644))
+
+	assert.Error(t, importRecords([]string{"-format", "tsv", "-key-class", "IntWritable", out, in}))
+	assertNoOutput(t, dir, "in.tsv")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+// A printer writes records to the output as text or JSON Lines, rendering
+// keys and values according to their classes.
+type printer struct {
+	w        *bufio.Writer
+	json     bool
+	keysOnly bool
+
+	key   renderer
+	value renderer
+}
+
+func newPrinter(flags *flag.FlagSet) *printer {
+	p := &printer{}
+	flags.BoolVar(&p.json, "json", false, "print records as JSON Lines")
+	flags.BoolVar(&p.keysOnly, "keys", false, "only print keys")
+	return p
+}
+
+// start prepares the printer to print records from a file with the given
+// header.
+func (p *printer) start(w io.Writer, header sequencefile.Header) {
+	if p.w == nil {
+		p.w = bufio.NewWriter(w)
+	}
+
+	p.key = rendererFor(header.KeyClassName)
+	p.value = rendererFor(header.ValueClassName)
+}
+
+func (p *printer) print(key, value []byte) error {
+	k, err := render(p.key, key)
+	if err != nil {
+		return fmt.Errorf("rendering key: %s", err)
+	}
+
+	var v interface{}
+	if !p.keysOnly {
+		v, err = render(p.value, value)
+		if err != nil {
+			return fmt.Errorf("rendering value: %s", err)
+		}
+	}
+
+	if p.json {
+		var b []byte
+		if p.keysOnly {
+			b, err = json.Marshal(struct {
+				Key interface{} `json:"key"`
+			}{k})
+		} else {
+			b, err = json.Marshal(struct {
+				Key   interface{} `json:"key"`
+				Value interface{} `json:"value"`
+			}{k, v})
+		}
+		if err != nil {
+			return err
+		}
+
+		p.w.Write(b)
+		return p.w.WriteByte('\n')
+	}
+
+	if p.keysOnly {
+		_, err = fmt.Fprintln(p.w, k)
+	} else {
+		_, err = fmt.Fprintf(p.w, "%v\t%v\n", k, v)
+	}
+
+	return err
+}
+
+func (p *printer) flush() error {
+	if p.w == nil {
+		return nil
+	}
+
+	return p.w.Flush()
+}
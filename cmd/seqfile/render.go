@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+// A renderer deserializes a Writable into a value suitable for printing or
+// encoding as JSON. The text form of the value matches the toString method
+// of the Writable in Hadoop.
+type renderer func(b []byte) interface{}
+
+func rendererFor(className string) renderer {
+	switch className {
+	case sequencefile.TextClassName:
+		return func(b []byte) interface{} { return sequencefile.Text(b) }
+	case sequencefile.IntWritableClassName:
+		return func(b []byte) interface{} { return sequencefile.IntWritable(b) }
+	case sequencefile.LongWritableClassName:
+		return func(b []byte) interface{} { return sequencefile.LongWritable(b) }
+	case sequencefile.BytesWritableClassName:
+		return func(b []byte) interface{} { return hexBytes(sequencefile.BytesWritable(b)) }
+	case sequencefile.NullWritableClassName:
+		return func(b []byte) interface{} { return "(null)" }
+	case sequencefile.BooleanWritableClassName:
+		return func(b []byte) interface{} { return sequencefile.BooleanWritable(b) }
+	case sequencefile.FloatWritableClassName:
+		return func(b []byte) interface{} { return javaFloat(float64(sequencefile.FloatWritable(b)), 32) }
+	case sequencefile.DoubleWritableClassName:
+		return func(b []byte) interface{} { return javaFloat(sequencefile.DoubleWritable(b), 64) }
+	case sequencefile.VIntWritableClassName:
+		return func(b []byte) interface{} { return sequencefile.VIntWritable(b) }
+	case sequencefile.VLongWritableClassName:
+		return func(b []byte) interface{} { return sequencefile.VLongWritable(b) }
+	default:
+		// We don't know how to deserialize it, so just print the raw bytes.
+		return func(b []byte) interface{} { return hexBytes(b) }
+	}
+}
+
+// render calls fn, turning the panics from the unwrap functions into errors.
+func render(fn renderer, b []byte) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return fn(b), nil
+}
+
+// hexBytes formats b the way BytesWritable.toString does, as space-separated
+// hex pairs.
+func hexBytes(b []byte) string {
+	var sb strings.Builder
+	for i, c := range b {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(hex.EncodeToString([]byte{c}))
+	}
+
+	return sb.String()
+}
+
+// A javaNumber is a number formatted the way Java's Float.toString and
+// Double.toString do. It marshals to a JSON number, or a string for NaN and
+// the infinities.
+type javaNumber string
+
+func (n javaNumber) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseFloat(string(n), 64); err != nil {
+		return []byte(strconv.Quote(string(n))), nil
+	}
+
+	return []byte(n), nil
+}
+
+func javaFloat(f float64, bitSize int) javaNumber {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	// Java switches to scientific notation outside of [10^-3, 10^7).
+	abs := math.Abs(f)
+	if abs == 0 || (abs >= 1e-3 && abs < 1e7) {
+		s := strconv.FormatFloat(f, 'f', -1, bitSize)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+
+		return javaNumber(s)
+	}
+
+	s := strconv.FormatFloat(f, 'E', -1, bitSize)
+	mantissa, exp, _ := strings.Cut(s, "E")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+
+	sign := ""
+	if strings.HasPrefix(exp, "-") {
+		sign = "-"
+	}
+
+	exp = strings.TrimLeft(exp, "+-0")
+	return javaNumber(mantissa + "E" + sign + exp)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJavaFloat(t *testing.T) {
+	cases := []struct {
+		f        float64
+		bitSize  int
+		expected javaNumber
+	}{
+		{0, 64, "0.0"},
+		{1, 64, "1.0"},
+		{-42.5, 64, "-42.5"},
+		{1.5, 32, "1.5"},
+		{1e7, 64, "1.0E7"},
+		{1.2345e10, 64, "1.2345E10"},
+		{0.0001, 64, "1.0E-4"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, javaFloat(c.f, c.bitSize))
+	}
+}
+
+func TestRender(t *testing.T) {
+	v, err := render(rendererFor(sequencefile.BytesWritableClassName), []byte{0, 0, 0, 2, 0xab, 0x01})
+	assert.NoError(t, err)
+	assert.Equal(t, "ab 01", v)
+
+	v, err = render(rendererFor(sequencefile.TextClassName), []byte{3, 'f', 'o', 'o'})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", v)
+
+	_, err = render(rendererFor(sequencefile.TextClassName), []byte{5, 'f', 'o', 'o'})
+	assert.Error(t, err)
+}
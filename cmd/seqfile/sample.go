@@ -0,0 +1,381 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+type record struct {
+	key, value []byte
+}
+
+func head(args []string) error {
+	flags := flag.NewFlagSet("head", flag.ExitOnError)
+	p := newPrinter(flags)
+	n := flags.Int("n", 10, "print the first `count` records of each file")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("head: no files given")
+	}
+
+	for _, path := range flags.Args() {
+		if err := headFile(p, path, *n); err != nil {
+			p.flush()
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return p.flush()
+}
+
+// headFile prints the first n records of a file.
+func headFile(p *printer, path string, n int) error {
+	sf, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	p.start(os.Stdout, sf.Header)
+	for i := 0; i < n && sf.Scan(); i++ {
+		if err := p.print(sf.Key(), sf.Value()); err != nil {
+			return err
+		}
+	}
+
+	return sf.Err()
+}
+
+func tail(args []string) error {
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	p := newPrinter(flags)
+	n := flags.Int("n", 10, "print the last `count` records of each file")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("tail: no files given")
+	}
+
+	for _, path := range flags.Args() {
+		if err := tailFile(p, path, *n); err != nil {
+			p.flush()
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return p.flush()
+}
+
+// tailInitialChunk is how far back from the end of the file tail starts
+// looking for sync markers.
+const tailInitialChunk = 256 * 1024
+
+// tailFile prints the last n records of a file, by syncing to a point near the
+// end of the file and reading forward. If that doesn't turn up enough records,
+// it tries again from further back.
+func tailFile(p *printer, path string, n int) error {
+	sf, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	size, err := sf.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	p.start(os.Stdout, sf.Header)
+	if n <= 0 {
+		return nil
+	}
+
+	for chunk := int64(tailInitialChunk); ; chunk *= 4 {
+		start := size - chunk
+		if start < 0 {
+			start = 0
+		}
+
+		// The last n records seen, as a ring buffer.
+		ring := make([]record, 0, n)
+		seen := 0
+		err := sf.Sync(start)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		for err == nil && sf.Scan() {
+			r := record{append([]byte(nil), sf.Key()...), append([]byte(nil), sf.Value()...)}
+			if len(ring) < n {
+				ring = append(ring, r)
+			} else {
+				ring[seen%n] = r
+			}
+
+			seen++
+		}
+
+		if sf.Err() != nil {
+			return sf.Err()
+		}
+
+		if seen < n && start > 0 {
+			continue
+		}
+
+		for i := 0; i < len(ring); i++ {
+			r := ring[(seen-len(ring)+i)%n]
+			if err := p.print(r.key, r.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// sampleSegmentSize is the largest granularity with which sample -fraction
+// picks sections of the file to read. Smaller files are split into at least
+// sampleMinSegments segments, so that they are sampled run by run.
+const (
+	sampleSegmentSize = 64 * 1024
+	sampleMinSegments = 1000
+)
+
+// syncLength is the length of a sync marker, including the escape before it.
+const syncLength = 4 + sequencefile.SyncSize
+
+func sample(args []string) error {
+	flags := flag.NewFlagSet("sample", flag.ExitOnError)
+	p := newPrinter(flags)
+	count := flags.Int("count", 0, "print `count` records from random points in each file")
+	fraction := flags.Float64("fraction", 0, "print approximately this `fraction` of the records in each file, in runs between sync markers")
+	seed := flags.Int64("seed", 0, "random `seed` (default: based on the current time)")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("sample: no files given")
+	} else if (*count > 0) == (*fraction > 0) {
+		return errors.New("sample: exactly one of -count or -fraction is required")
+	} else if *fraction > 1 {
+		return errors.New("sample: -fraction must be between 0 and 1")
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(*seed))
+
+	for _, path := range flags.Args() {
+		var err error
+		if *count > 0 {
+			err = sampleCount(p, rnd, path, *count)
+		} else {
+			err = sampleFraction(p, rnd, path, *fraction)
+		}
+
+		if err != nil {
+			p.flush()
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return p.flush()
+}
+
+// sampleCount prints about n records, picked at random from runs of records
+// between sync markers. Each of n random offsets picks the run following it,
+// wrapping around to the first run at the end of the file, so that every run
+// can be picked, even if there's only one. A run picked k times contributes k
+// distinct records, chosen at random, so fewer than n records are printed only
+// if the picked runs are too short.
+func sampleCount(p *printer, rnd *rand.Rand, path string, n int) error {
+	sf, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	first := sf.Offset()
+	size, err := sf.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	p.start(os.Stdout, sf.Header)
+	if size <= first {
+		return nil
+	}
+
+	picks := make(map[int64]int)
+	for i := 0; i < n; i++ {
+		err := sf.Sync(first + rnd.Int63n(size-first))
+		if err == io.EOF {
+			picks[first]++
+		} else if err != nil {
+			return err
+		} else {
+			picks[sf.Offset()]++
+		}
+	}
+
+	runs := make([]int64, 0, len(picks))
+	for run := range picks {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i] < runs[j] })
+
+	for _, run := range runs {
+		if err := sf.Sync(run); err != nil {
+			return err
+		}
+
+		// Pick k of the records in the run, and print them in order.
+		k := picks[run]
+		var picked []sampledRecord
+		_, err := scanRun(sf, func(i int) error {
+			j := len(picked)
+			if j == k {
+				j = rnd.Intn(i + 1)
+				if j >= k {
+					return nil
+				}
+			} else {
+				picked = append(picked, sampledRecord{})
+			}
+
+			picked[j] = sampledRecord{record{append([]byte(nil), sf.Key()...), append([]byte(nil), sf.Value()...)}, i}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(picked, func(i, j int) bool { return picked[i].index < picked[j].index })
+		for _, r := range picked {
+			if err := p.print(r.key, r.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// A sampledRecord is a record picked by sampleCount, along with its index in
+// its run.
+type sampledRecord struct {
+	record
+	index int
+}
+
+// sampleFraction prints each run of records between sync markers with the
+// given probability. The file is divided into segments, and a run is printed
+// if the segment containing its start is picked, so that only the picked
+// segments need to be read.
+func sampleFraction(p *printer, rnd *rand.Rand, path string, fraction float64) error {
+	sf, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	size, err := sf.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	p.start(os.Stdout, sf.Header)
+	segment := size / sampleMinSegments
+	if segment > sampleSegmentSize {
+		segment = sampleSegmentSize
+	} else if segment < 1 {
+		segment = 1
+	}
+
+	// The start of the first run at or after the current segment, if it's
+	// known, so that segments without one can be skipped without searching.
+	next := int64(-1)
+	for start := int64(0); start < size; start += segment {
+		end := start + segment
+		if next >= end || rnd.Float64() >= fraction {
+			continue
+		}
+
+		off := start
+		if next > start {
+			off = next
+		}
+
+		for {
+			err := sf.Sync(off)
+			if err == io.EOF {
+				next = size
+				break
+			} else if err != nil {
+				return err
+			}
+
+			run := sf.Offset()
+			next = run
+			if next >= end {
+				break
+			}
+
+			// A run starting before the segment belonged to an earlier one.
+			if run >= start {
+				last, err := scanRun(sf, func(int) error {
+					return p.print(sf.Key(), sf.Value())
+				})
+				if err != nil {
+					return err
+				}
+
+				off = last + 1
+			}
+
+			// In block-compressed files, a run starts at the sync marker before
+			// its first block, so search from past the marker to avoid finding
+			// it again.
+			if min := run + syncLength; off < min {
+				off = min
+			}
+		}
+	}
+
+	return nil
+}
+
+// scanRun calls fn for each record in the run following the current position
+// of the Reader, up to the next sync marker, with the index of the record in
+// the run. It returns the offset of the last record, or of the start of the
+// run if it's empty.
+func scanRun(sf *sequencefile.Reader, fn func(i int) error) (int64, error) {
+	last := sf.Offset()
+	var syncs int64
+	for i := 0; sf.Scan(); i++ {
+		// In block-compressed files, the first block is preceded by a sync
+		// marker too, so count from the first record.
+		if i == 0 {
+			syncs = sf.Stats().SyncMarkers
+		} else if sf.Stats().SyncMarkers != syncs {
+			break
+		}
+
+		last = sf.Offset()
+		if err := fn(i); err != nil {
+			return 0, err
+		}
+	}
+
+	return last, sf.Err()
+}
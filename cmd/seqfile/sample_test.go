@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSampleFiles writes a small uncompressed file with 3000 records in many
+// runs between sync markers, and a block-compressed file with 100 records in a
+// single block.
+func writeSampleFiles(t *testing.T) (small, single string) {
+	dir := t.TempDir()
+	small, single = filepath.Join(dir, "small"), filepath.Join(dir, "single")
+	writeLongs(t, small, counting(3000)...)
+	writeFile(t, single, sequencefile.WriterConfig{
+		Compression:      sequencefile.BlockCompression,
+		CompressionCodec: sequencefile.GzipCompression,
+	}, counting(100)...)
+
+	return small, single
+}
+
+func TestHeadTail(t *testing.T) {
+	small, single := writeSampleFiles(t)
+
+	out, err := captureStdout(t, head, "-keys", "-n", "3", small, single)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 1, 2, 0, 1, 2}, parseKeys(t, out))
+
+	out, err = captureStdout(t, tail, "-keys", "-n", "3", small, single)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2997, 2998, 2999, 97, 98, 99}, parseKeys(t, out))
+
+	out, err = captureStdout(t, tail, "-keys", "-n", "200", single)
+	require.NoError(t, err)
+	assert.Equal(t, counting(100), parseKeys(t, out))
+	// Records printed before an error still make it out.
+	missing := filepath.Join(t.TempDir(), "missing")
+	out, err = captureStdout(t, head, "-keys", "-n", "2", small, missing)
+	assert.Error(t, err)
+	assert.Equal(t, []int64{0, 1}, parseKeys(t, out))
+
+	out, err = captureStdout(t, tail, "-keys", "-n", "2", small, missing)
+	assert.Error(t, err)
+	assert.Equal(t, []int64{2998, 2999}, parseKeys(t, out))
+}
+
+func TestSampleCount(t *testing.T) {
+	small, single := writeSampleFiles(t)
+	for _, path := range []string{small, single} {
+		var all []int64
+		for seed := 1; seed <= 10; seed++ {
+			out, err := captureStdout(t, sample, "-keys", "-count", "20", "-seed", fmt.Sprint(seed), path)
+			require.NoError(t, err)
+
+			keys := parseKeys(t, out)
+			assert.Len(t, keys, 20, "seed %d", seed)
+			assert.True(t, sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i] <= keys[j] }),
+				"the sampled records should be distinct, and in order")
+			all = append(all, keys...)
+		}
+
+		// The samples should be spread across the whole file.
+		max := int64(3000)
+		if path == single {
+			max = 100
+		}
+
+		sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+		assert.Less(t, all[len(all)/4], max/2, path)
+		assert.Greater(t, all[len(all)*3/4], max/2, path)
+	}
+}
+
+func TestSampleFraction(t *testing.T) {
+	small, single := writeSampleFiles(t)
+	for _, fraction := range []float64{0.1, 0.5} {
+		total := 0
+		for seed := 1; seed <= 20; seed++ {
+			out, err := captureStdout(t, sample, "-keys", "-fraction", fmt.Sprint(fraction), "-seed", fmt.Sprint(seed), small)
+			require.NoError(t, err)
+
+			keys := parseKeys(t, out)
+			assert.True(t, sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i] < keys[j] }))
+			total += len(keys)
+		}
+
+		expected := fraction * 3000
+		assert.InDelta(t, expected, float64(total)/20, expected/3, "fraction %v", fraction)
+	}
+
+	// With only one run, the file is either printed in full or not at all.
+	counts := make(map[int]int)
+	for seed := 1; seed <= 20; seed++ {
+		out, err := captureStdout(t, sample, "-keys", "-fraction", "0.5", "-seed", fmt.Sprint(seed), single)
+		require.NoError(t, err)
+		counts[len(parseKeys(t, out))]++
+	}
+
+	assert.Len(t, counts, 2)
+	assert.Greater(t, counts[0], 0)
+	assert.Greater(t, counts[100], 0)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+func importRecords(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "json", "input `format`: json (JSON Lines) or tsv")
+	keyClass := flags.String("key-class", "Text", "Writable `class` of the keys")
+	valueClass := flags.String("value-class", "Text", "Writable `class` of the values")
+	compression := flags.String("compression", "none", "compression `type`: none, record or block")
+	codec := flags.String("codec", "", "compression `codec`: gzip, snappy, zlib, zstd or bzip2")
+	metadata := metadataFlag{}
+	flags.Var(metadata, "meta", "add a `key=value` pair to the metadata (can be repeated)")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return errors.New("import: expected an output file, and optionally an input file")
+	}
+
+	cfg := &sequencefile.WriterConfig{
+		KeyClass:   resolveClass(*keyClass),
+		ValueClass: resolveClass(*valueClass),
+		Metadata:   metadata,
+	}
+
+	var err error
+	if cfg.Compression, err = parseCompression(*compression); err != nil {
+		return err
+	}
+	if *codec != "" {
+		if cfg.CompressionCodec, err = parseCodec(*codec); err != nil {
+			return err
+		}
+	}
+
+	var parse func(line []byte) (k, v interface{}, err error)
+	switch *format {
+	case "json":
+		parse = parseJSONRecord
+	case "tsv":
+		parse = parseTSVRecord
+	default:
+		return fmt.Errorf("import: unknown format %q", *format)
+	}
+
+	var in io.Reader = os.Stdin
+	if flags.NArg() == 2 {
+		f, err := os.Open(flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	o, err := createOutput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cfg.Writer = o
+	w, err := sequencefile.NewRawWriter(cfg)
+	if err != nil {
+		o.Abort()
+		return err
+	}
+
+	err = importLines(w, in, parse, cfg.KeyClass, cfg.ValueClass)
+	if err != nil {
+		o.Abort()
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		o.Abort()
+		return err
+	}
+
+	return o.Commit()
+}
+
+func importLines(w *sequencefile.Writer, in io.Reader, parse func([]byte) (k, v interface{}, err error), keyClass, valueClass string) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<30)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		k, v, err := parse(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+
+		key, err := importValue(keyClass, k)
+		if err != nil {
+			return fmt.Errorf("line %d: key: %s", line, err)
+		}
+
+		value, err := importValue(valueClass, v)
+		if err != nil {
+			return fmt.Errorf("line %d: value: %s", line, err)
+		}
+
+		if err := w.AppendRaw(key, value); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseJSONRecord(line []byte) (k, v interface{}, err error) {
+	var record struct {
+		Key   interface{} `json:"key"`
+		Value interface{} `json:"value"`
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&record); err != nil {
+		return nil, nil, err
+	}
+
+	return record.Key, record.Value, nil
+}
+
+func parseTSVRecord(line []byte) (k, v interface{}, err error) {
+	key, value, ok := strings.Cut(string(line), "\t")
+	if !ok {
+		return nil, nil, errors.New("expected a tab-separated key and value")
+	}
+
+	return tsvField(key), tsvField(value), nil
+}
+
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("export: no files given")
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	for _, path := range flags.Args() {
+		if err := exportFile(w, path); err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return w.Flush()
+}
+
+func exportFile(w io.Writer, path string) error {
+	sf, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for sf.Scan() {
+		k, err := exportValue(sf.Header.KeyClassName, sf.Key())
+		if err != nil {
+			return fmt.Errorf("key: %s", err)
+		}
+
+		v, err := exportValue(sf.Header.ValueClassName, sf.Value())
+		if err != nil {
+			return fmt.Errorf("value: %s", err)
+		}
+
+		err = enc.Encode(struct {
+			Key   interface{} `json:"key"`
+			Value interface{} `json:"value"`
+		}{k, v})
+		if err != nil {
+			return err
+		}
+	}
+
+	return sf.Err()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+// resolveClass expands the short name of a Writable in org.apache.hadoop.io,
+// like "Text", into the fully-qualified class name.
+func resolveClass(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+
+	return "org.apache.hadoop.io." + name
+}
+
+// exportValue deserializes a Writable into a value that can be encoded as JSON
+// without losing information, such that importValue can reverse it. Bytes,
+// including the serialized form of Writables this package doesn't understand,
+// are encoded as base64.
+func exportValue(className string, b []byte) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	switch className {
+	case sequencefile.TextClassName:
+		s := sequencefile.Text(b)
+		if !utf8.ValidString(s) {
+			return nil, fmt.Errorf("Text is not valid UTF-8: %q", s)
+		}
+		return s, nil
+	case sequencefile.IntWritableClassName:
+		return sequencefile.IntWritable(b), nil
+	case sequencefile.LongWritableClassName:
+		return sequencefile.LongWritable(b), nil
+	case sequencefile.BytesWritableClassName:
+		return base64.StdEncoding.EncodeToString(sequencefile.BytesWritable(b)), nil
+	case sequencefile.NullWritableClassName:
+		return nil, nil
+	case sequencefile.BooleanWritableClassName:
+		return sequencefile.BooleanWritable(b), nil
+	case sequencefile.FloatWritableClassName:
+		return exportFloat(float64(sequencefile.FloatWritable(b)), 32), nil
+	case sequencefile.DoubleWritableClassName:
+		return exportFloat(sequencefile.DoubleWritable(b), 64), nil
+	case sequencefile.VIntWritableClassName:
+		return sequencefile.VIntWritable(b), nil
+	case sequencefile.VLongWritableClassName:
+		return sequencefile.VLongWritable(b), nil
+	default:
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+}
+
+// exportFloat returns f as a JSON number, or as a string if it can't be
+// represented as one.
+func exportFloat(f float64, bitSize int) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+// importValue serializes a value decoded from JSON (with UseNumber) or read
+// from a TSV field as the given Writable class.
+func importValue(className string, v interface{}) ([]byte, error) {
+	var value interface{}
+	var err error
+	switch className {
+	case sequencefile.TextClassName:
+		value, err = importString(v)
+	case sequencefile.IntWritableClassName, sequencefile.VIntWritableClassName:
+		var n int64
+		n, err = importInt(v, 32)
+		value = int32(n)
+	case sequencefile.LongWritableClassName, sequencefile.VLongWritableClassName:
+		value, err = importInt(v, 64)
+	case sequencefile.BytesWritableClassName:
+		value, err = importBytes(v)
+	case sequencefile.NullWritableClassName:
+		// In TSV, a null is written as an empty field, or the way cat renders
+		// it.
+		if s, ok := v.(tsvField); v != nil && !(ok && (s == "" || s == "(null)")) {
+			err = fmt.Errorf("expected null, got %v", v)
+		}
+	case sequencefile.BooleanWritableClassName:
+		value, err = importBool(v)
+	case sequencefile.FloatWritableClassName:
+		var f float64
+		f, err = importFloat(v, 32)
+		value = float32(f)
+	case sequencefile.DoubleWritableClassName:
+		value, err = importFloat(v, 64)
+	default:
+		// For classes we don't know how to serialize, the value must be the raw
+		// serialized bytes.
+		return importBytes(v)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ww, err := sequencefile.NewWritableWriter(className)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = ww(&buf, value)
+	return buf.Bytes(), err
+}
+
+// A tsvField is a field read from tab-separated input. Unlike JSON strings,
+// it's used verbatim for BytesWritable, rather than decoded as base64.
+type tsvField string
+
+func importString(v interface{}) (string, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case tsvField:
+		return string(s), nil
+	case json.Number:
+		return s.String(), nil
+	default:
+		return "", fmt.Errorf("expected a string, got %v", v)
+	}
+}
+
+func importInt(v interface{}, bitSize int) (int64, error) {
+	s, err := importString(v)
+	if err != nil {
+		return 0, fmt.Errorf("expected an integer, got %v", v)
+	}
+
+	return strconv.ParseInt(s, 10, bitSize)
+}
+
+func importFloat(v interface{}, bitSize int) (float64, error) {
+	s, err := importString(v)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number, got %v", v)
+	}
+
+	switch s {
+	case "Infinity":
+		s = "+Inf"
+	case "-Infinity":
+		s = "-Inf"
+	}
+
+	return strconv.ParseFloat(s, bitSize)
+}
+
+func importBool(v interface{}) (bool, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+
+	s, err := importString(v)
+	if err != nil {
+		return false, fmt.Errorf("expected a boolean, got %v", v)
+	}
+
+	return strconv.ParseBool(s)
+}
+
+func importBytes(v interface{}) ([]byte, error) {
+	switch s := v.(type) {
+	case tsvField:
+		return []byte(s), nil
+	case string:
+		return base64.StdEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("expected a base64 string, got %v", v)
+	}
+}
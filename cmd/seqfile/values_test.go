@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	values := []struct {
+		className string
+		value     interface{}
+	}{
+		{sequencefile.TextClassName, "foo"},
+		{sequencefile.TextClassName, "ünïcødé"},
+		{sequencefile.IntWritableClassName, int32(-42)},
+		{sequencefile.LongWritableClassName, int64(math.MaxInt64)},
+		{sequencefile.BytesWritableClassName, []byte{0, 1, 2, 0xff}},
+		{sequencefile.NullWritableClassName, nil},
+		{sequencefile.BooleanWritableClassName, true},
+		{sequencefile.FloatWritableClassName, float32(0.1)},
+		{sequencefile.DoubleWritableClassName, 0.1},
+		{sequencefile.DoubleWritableClassName, math.Inf(-1)},
+		{sequencefile.VIntWritableClassName, int32(1000)},
+		{sequencefile.VLongWritableClassName, int64(-1000000)},
+	}
+
+	for _, v := range values {
+		ww, err := sequencefile.NewWritableWriter(v.className)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, ww(&buf, v.value))
+
+		exported, err := exportValue(v.className, buf.Bytes())
+		require.NoError(t, err)
+
+		// Go through an actual JSON encoding.
+		b, err := json.Marshal(exported)
+		require.NoError(t, err)
+
+		var decoded interface{}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		require.NoError(t, dec.Decode(&decoded))
+
+		imported, err := importValue(v.className, decoded)
+		require.NoError(t, err)
+		assert.Equal(t, buf.Bytes(), imported, "%s %v should round trip", v.className, v.value)
+	}
+}
+
+func TestImportTSV(t *testing.T) {
+	b, err := importValue(sequencefile.BytesWritableClassName, tsvField("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foo"), sequencefile.BytesWritable(b))
+
+	b, err = importValue(sequencefile.IntWritableClassName, tsvField("12"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(12), sequencefile.IntWritable(b))
+
+	_, err = importValue(sequencefile.IntWritableClassName, tsvField("foo"))
+	assert.Error(t, err)
+}
+
+func TestImportTSVNull(t *testing.T) {
+	// The way cat renders a NullWritable should import as one.
+	rendered, err := render(rendererFor(sequencefile.NullWritableClassName), nil)
+	require.NoError(t, err)
+
+	for _, field := range []string{fmt.Sprint(rendered), ""} {
+		b, err := importValue(sequencefile.NullWritableClassName, tsvField(field))
+		require.NoError(t, err)
+		assert.Empty(t, b)
+	}
+
+	_, err = importValue(sequencefile.NullWritableClassName, tsvField("foo"))
+	assert.Error(t, err)
+
+	// A JSON null is still fine, but not the string "(null)".
+	_, err = importValue(sequencefile.NullWritableClassName, nil)
+	assert.NoError(t, err)
+	_, err = importValue(sequencefile.NullWritableClassName, "(null)")
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+var errVerifyFailed = errors.New("some files failed verification")
+
+func verify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	quiet := flags.Bool("q", false, "only print files with problems")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("verify: no files given")
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		report, err := sequencefile.VerifyFile(path)
+		if err != nil {
+			fmt.Printf("%s: %s\n", path, err)
+			failed = true
+			continue
+		}
+
+		if report.OK() && *quiet {
+			continue
+		}
+
+		fmt.Printf("%s: %s\n", path, report)
+		for _, p := range report.Problems {
+			fmt.Printf("  bytes %d-%d: %s\n", p.Start, p.End, p.Err)
+		}
+
+		if !report.OK() {
+			failed = true
+		}
+	}
+
+	if failed {
+		return errVerifyFailed
+	}
+
+	return nil
+}
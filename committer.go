@@ -0,0 +1,246 @@
+package sequencefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/colinmarc/sequencefile/internal/pending"
+)
+
+const (
+	temporaryDir  = "_temporary"
+	successMarker = "_SUCCESS"
+
+	// Like Hadoop, we put everything under an application attempt directory,
+	// but there's only ever one.
+	jobAttempt = "0"
+)
+
+// An OutputCommitter manages writing the output of a job to a directory, in
+// the same way as Hadoop's FileOutputCommitter (with the original, "v1"
+// algorithm).
+//
+// Each task attempt writes its files under _temporary/0/_temporary/<attempt>/
+// in the output directory. When an attempt commits, its directory is renamed
+// to _temporary/0/<task>/ in a single step, so either all of its files are
+// committed or none are, and only one attempt at each task can commit. Once
+// all the tasks are committed, committing the job moves their files into the
+// output directory, removes everything left by attempts that were aborted or
+// never committed, and writes an empty _SUCCESS file, which readers can wait
+// for to know the output is complete.
+type OutputCommitter struct {
+	dir string
+}
+
+// NewOutputCommitter returns an OutputCommitter for the given output
+// directory, creating it if necessary.
+func NewOutputCommitter(dir string) (*OutputCommitter, error) {
+	err := os.MkdirAll(filepath.Join(dir, temporaryDir, jobAttempt, temporaryDir), 0777)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutputCommitter{dir: dir}, nil
+}
+
+// Dir returns the output directory.
+func (c *OutputCommitter) Dir() string {
+	return c.dir
+}
+
+// NewTaskAttempt starts a new task attempt with the given ID. Like Hadoop's
+// task attempt IDs, such as "attempt_0001_r_000003_0", the ID must end with
+// the number of the attempt, and the rest identifies the task. It's an error
+// to reuse the ID of an attempt that is still in progress.
+func (c *OutputCommitter) NewTaskAttempt(attempt string) (*TaskAttempt, error) {
+	task, ok := taskID(attempt)
+	if !ok || !isPlainName(attempt) {
+		return nil, fmt.Errorf("sequencefile: invalid attempt ID: %q", attempt)
+	}
+
+	dir := filepath.Join(c.jobDir(), temporaryDir, attempt)
+	if err := os.Mkdir(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	return &TaskAttempt{c: c, dir: dir, taskDir: filepath.Join(c.jobDir(), task)}, nil
+}
+
+// Commit moves the files from every committed task into the output directory,
+// then removes the temporary directory, along with anything left in it by
+// uncommitted attempts, and finally writes the _SUCCESS file. If any of the
+// files already exists in the output directory, Commit returns an error
+// before moving anything.
+func (c *OutputCommitter) Commit() error {
+	tasks, err := os.ReadDir(c.jobDir())
+	if err != nil {
+		return err
+	}
+
+	var files [][2]string
+	for _, task := range tasks {
+		if task.Name() == temporaryDir {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(c.jobDir(), task.Name()))
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			src := filepath.Join(c.jobDir(), task.Name(), e.Name())
+			dst := filepath.Join(c.dir, e.Name())
+			if _, err := os.Lstat(dst); err == nil {
+				return fmt.Errorf("sequencefile: output file already exists: %s", dst)
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+
+			files = append(files, [2]string{src, dst})
+		}
+	}
+
+	for _, f := range files {
+		if err := os.Rename(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(c.dir, temporaryDir)); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(c.dir, successMarker))
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// Abort removes the temporary directory, along with the output of every task,
+// committed or not. The job is never marked successful.
+func (c *OutputCommitter) Abort() error {
+	return os.RemoveAll(filepath.Join(c.dir, temporaryDir))
+}
+
+func (c *OutputCommitter) jobDir() string {
+	return filepath.Join(c.dir, temporaryDir, jobAttempt)
+}
+
+// taskID returns the ID of the task for an attempt ID, which is the attempt ID
+// without the attempt number. Like Hadoop, it also replaces the "attempt_"
+// prefix with "task_".
+func taskID(attempt string) (string, bool) {
+	i := strings.LastIndexByte(attempt, '_')
+	if i <= 0 {
+		return "", false
+	} else if _, err := strconv.ParseUint(attempt[i+1:], 10, 32); err != nil {
+		return "", false
+	}
+
+	return "task_" + strings.TrimPrefix(attempt[:i], "attempt_"), true
+}
+
+// A TaskAttempt writes files for a single attempt at a task, which only
+// become visible in the output directory once the attempt, and then the job,
+// are committed.
+type TaskAttempt struct {
+	c       *OutputCommitter
+	dir     string
+	taskDir string
+	files   []*taskFile
+	done    bool
+}
+
+type taskFile struct {
+	w *Writer
+	f *pending.File
+}
+
+// Create starts a new SequenceFile with the given name, like "part-00003",
+// and returns a Writer for it. The Writer field of cfg is ignored. As with
+// NewRawWriter, the Writer accepts classes that this package doesn't know how
+// to serialize, for use with AppendRaw.
+//
+// The Writer may be closed before committing the attempt; any that aren't
+// are closed by Commit or Abort.
+func (t *TaskAttempt) Create(name string, cfg *WriterConfig) (*Writer, error) {
+	if t.done {
+		return nil, fmt.Errorf("sequencefile: task attempt already finished")
+	} else if !isPlainName(name) {
+		return nil, fmt.Errorf("sequencefile: invalid file name: %q", name)
+	}
+
+	for _, tf := range t.files {
+		if filepath.Base(tf.f.Path()) == name {
+			return nil, fmt.Errorf("sequencefile: file already created: %s", name)
+		}
+	}
+
+	f, err := pending.Create(filepath.Join(t.dir, name), filepath.Join(t.c.dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	wcfg := *cfg
+	wcfg.Writer = f
+	w, err := NewRawWriter(&wcfg)
+	if err != nil {
+		f.Abort()
+		return nil, err
+	}
+
+	t.files = append(t.files, &taskFile{w: w, f: f})
+	return w, nil
+}
+
+// Commit closes any open Writers, and commits the attempt's files by renaming
+// its directory, so that they're moved into the output directory when the job
+// is committed. If another attempt at the same task was already committed,
+// Commit returns an error, and the attempt should be aborted.
+func (t *TaskAttempt) Commit() error {
+	if t.done {
+		return fmt.Errorf("sequencefile: task attempt already finished")
+	}
+
+	// Closing a Writer again is harmless.
+	for _, tf := range t.files {
+		if err := tf.w.Close(); err != nil {
+			return err
+		}
+	}
+
+	// Renaming a directory fails if the destination has files in it, which
+	// catches attempts committing at the same time.
+	if _, err := os.Lstat(t.taskDir); err == nil {
+		return fmt.Errorf("sequencefile: task already committed: %s", filepath.Base(t.taskDir))
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(t.dir, t.taskDir); err != nil {
+		return err
+	}
+
+	t.done = true
+	return nil
+}
+
+// Abort discards any open Writers, and removes all of the attempt's files.
+func (t *TaskAttempt) Abort() error {
+	t.done = true
+	for _, tf := range t.files {
+		tf.f.Abort()
+	}
+
+	return os.RemoveAll(t.dir)
+}
+
+func isPlainName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
@@ -0,0 +1,147 @@
+package sequencefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	return names
+}
+
+func TestOutputCommitter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	c, err := NewOutputCommitter(dir)
+	require.NoError(t, err)
+
+	cfg := &WriterConfig{KeyClass: LongWritableClassName, ValueClass: TextClassName}
+
+	// The first attempt at task 0 fails halfway through.
+	failed, err := c.NewTaskAttempt("attempt_r_000000_0")
+	require.NoError(t, err)
+	w, err := failed.Create("part-00000", cfg)
+	require.NoError(t, err)
+	require.NoError(t, w.Append(int64(-1), "bad"))
+	require.NoError(t, failed.Abort())
+
+	for task, name := range []string{"part-00000", "part-00001"} {
+		a, err := c.NewTaskAttempt(fmt.Sprintf("attempt_r_%06d_1", task))
+		require.NoError(t, err)
+
+		w, err := a.Create(name, cfg)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, w.Append(int64(task*10+i), "foo"))
+		}
+
+		require.NoError(t, a.Commit())
+		assert.NotContains(t, listDir(t, dir), name, "nothing should be visible before the job is committed")
+	}
+
+	// A duplicate attempt at task 1, for example from speculative execution,
+	// can't replace or add to the committed output.
+	dup, err := c.NewTaskAttempt("attempt_r_000001_2")
+	require.NoError(t, err)
+	for _, name := range []string{"part-00001", "side-00001"} {
+		w, err = dup.Create(name, cfg)
+		require.NoError(t, err)
+		require.NoError(t, w.Append(int64(-1), "bad"))
+	}
+	assert.Error(t, dup.Commit())
+
+	// An attempt that never finishes is cleaned up with the job.
+	_, err = c.NewTaskAttempt("attempt_r_000002_0")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Commit())
+	assert.Equal(t, []string{"_SUCCESS", "part-00000", "part-00001"}, listDir(t, dir))
+
+	m, err := OpenDir(dir)
+	require.NoError(t, err)
+
+	n := int64(0)
+	for m.Scan() {
+		require.Equal(t, n, LongWritable(m.Key()))
+		n++
+	}
+
+	require.NoError(t, m.Err())
+	assert.Equal(t, int64(20), n)
+}
+
+func TestOutputCommitterAbort(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewOutputCommitter(dir)
+	require.NoError(t, err)
+
+	a, err := c.NewTaskAttempt("attempt_r_000000_0")
+	require.NoError(t, err)
+	_, err = a.Create("part-00000", &WriterConfig{})
+	require.NoError(t, err)
+
+	_, err = a.Create("part-00000", &WriterConfig{})
+	assert.Error(t, err, "creating the same file twice should fail")
+	_, err = a.Create("../part-00000", &WriterConfig{})
+	assert.Error(t, err, "files should be created in the attempt directory")
+
+	require.NoError(t, c.Abort())
+	assert.Empty(t, listDir(t, dir))
+}
+
+func TestOutputCommitterConcurrentAttempts(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewOutputCommitter(dir)
+	require.NoError(t, err)
+
+	cfg := &WriterConfig{KeyClass: LongWritableClassName, ValueClass: TextClassName}
+	var attempts []*TaskAttempt
+	for i := 0; i < 2; i++ {
+		a, err := c.NewTaskAttempt(fmt.Sprintf("attempt_0001_m_000000_%d", i))
+		require.NoError(t, err)
+		for _, name := range []string{"part-00000", fmt.Sprintf("extra-%d", i)} {
+			w, err := a.Create(name, cfg)
+			require.NoError(t, err)
+			require.NoError(t, w.Append(int64(i), "foo"))
+		}
+
+		attempts = append(attempts, a)
+	}
+
+	// Both attempts finish, but only the first to commit wins, and all of its
+	// files are committed together.
+	require.NoError(t, attempts[1].Commit())
+	assert.Error(t, attempts[0].Commit())
+	require.NoError(t, attempts[0].Abort())
+
+	require.NoError(t, c.Commit())
+	assert.Equal(t, []string{"_SUCCESS", "extra-1", "part-00000"}, listDir(t, dir))
+
+	r, err := Open(filepath.Join(dir, "part-00000"))
+	require.NoError(t, err)
+	defer r.Close()
+	require.True(t, r.Scan())
+	assert.Equal(t, int64(1), LongWritable(r.Key()))
+}
+
+func TestOutputCommitterInvalidAttempt(t *testing.T) {
+	c, err := NewOutputCommitter(t.TempDir())
+	require.NoError(t, err)
+
+	for _, id := range []string{"", "attempt", "attempt_r_000000_x", "../attempt_0"} {
+		_, err := c.NewTaskAttempt(id)
+		assert.Error(t, err, id)
+	}
+}
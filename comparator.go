@@ -0,0 +1,201 @@
+package sequencefile
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A RawComparator compares two serialized Writables, as returned by
+// Reader.Key, without fully deserializing them. It returns a negative number
+// if a sorts before b, zero if they are equal, and a positive number if a
+// sorts after b, in the same order as the class's WritableComparator in
+// Hadoop.
+//
+// The comparators returned by NewRawComparator never panic on malformed
+// input. Like Hadoop's, the ones for Text and BytesWritable skip over the
+// length without checking it. For the other classes, values that can't be
+// decoded sort before any that can, and by their bytes among themselves.
+type RawComparator func(a, b []byte) int
+
+func compareBytesWritable(a, b []byte) int {
+	return bytes.Compare(skip(a, 4), skip(b, 4))
+}
+
+func compareText(a, b []byte) int {
+	return bytes.Compare(textBytes(a), textBytes(b))
+}
+
+// textBytes returns the UTF-8 bytes of a serialized Text, without the length.
+// Like Hadoop's Text.Comparator, it skips over the length without decoding it.
+func textBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+
+	return skip(b, decodeVIntSize(b[0]))
+}
+
+// skip returns b without the first n bytes, or an empty slice if it's shorter
+// than that.
+func skip(b []byte, n int) []byte {
+	if n > len(b) {
+		return b[len(b):]
+	}
+
+	return b[n:]
+}
+
+// decodeVIntSize returns the total size of a VInt, given its first byte, like
+// WritableUtils.decodeVIntSize.
+func decodeVIntSize(b byte) int {
+	l := int8(b)
+	if l >= -112 {
+		return 1
+	} else if l < -120 {
+		return int(-119 - l)
+	}
+
+	return int(-111 - l)
+}
+
+// decodeVLong decodes a VInt or VLong that fills b exactly, returning false if
+// it's malformed. Unlike ReadVInt, it doesn't allocate.
+func decodeVLong(b []byte) (int64, bool) {
+	if len(b) == 0 || decodeVIntSize(b[0]) != len(b) {
+		return 0, false
+	} else if len(b) == 1 {
+		return int64(int8(b[0])), true
+	}
+
+	var res uint64
+	for _, c := range b[1:] {
+		res = (res << 8) | uint64(c)
+	}
+
+	if int8(b[0]) < -120 {
+		res = ^res
+	}
+
+	return int64(res), true
+}
+
+// compareValid compares a and b with cmp if they're both valid. Otherwise,
+// invalid values sort first, by their bytes, so that the order is consistent.
+func compareValid(a, b []byte, validA, validB bool, cmp RawComparator) int {
+	switch {
+	case validA && validB:
+		return cmp(a, b)
+	case validA:
+		return 1
+	case validB:
+		return -1
+	default:
+		return bytes.Compare(a, b)
+	}
+}
+
+// compareFixed is like compareValid, for classes that are always n bytes.
+func compareFixed(a, b []byte, n int, cmp RawComparator) int {
+	return compareValid(a, b, len(a) == n, len(b) == n, cmp)
+}
+
+func compareInt(a, b []byte) int {
+	return compareFixed(a, b, 4, func(a, b []byte) int {
+		return compareInt64(int64(IntWritable(a)), int64(IntWritable(b)))
+	})
+}
+
+func compareLong(a, b []byte) int {
+	return compareFixed(a, b, 8, func(a, b []byte) int {
+		return compareInt64(LongWritable(a), LongWritable(b))
+	})
+}
+
+func compareNull(a, b []byte) int {
+	return 0
+}
+
+func compareBoolean(a, b []byte) int {
+	return compareFixed(a, b, 1, func(a, b []byte) int {
+		x, y := BooleanWritable(a), BooleanWritable(b)
+		if x == y {
+			return 0
+		} else if !x {
+			return -1
+		}
+
+		return 1
+	})
+}
+
+// compareFloat and compareDouble match the comparison in Java, in which any
+// comparison involving NaN returns 1.
+func compareFloat(a, b []byte) int {
+	return compareFixed(a, b, 4, func(a, b []byte) int {
+		return compareFloat64(float64(FloatWritable(a)), float64(FloatWritable(b)))
+	})
+}
+
+func compareDouble(a, b []byte) int {
+	return compareFixed(a, b, 8, func(a, b []byte) int {
+		return compareFloat64(DoubleWritable(a), DoubleWritable(b))
+	})
+}
+
+// compareVLong compares VIntWritables as well as VLongWritables, since they're
+// encoded the same way.
+func compareVLong(a, b []byte) int {
+	x, validA := decodeVLong(a)
+	y, validB := decodeVLong(b)
+	return compareValid(a, b, validA, validB, func(_, _ []byte) int {
+		return compareInt64(x, y)
+	})
+}
+
+func compareInt64(x, y int64) int {
+	if x < y {
+		return -1
+	} else if x == y {
+		return 0
+	}
+
+	return 1
+}
+
+func compareFloat64(x, y float64) int {
+	if x < y {
+		return -1
+	} else if x == y {
+		return 0
+	}
+
+	return 1
+}
+
+// NewRawComparator gets a RawComparator for a given Hadoop class name.
+func NewRawComparator(className string) (RawComparator, error) {
+	switch className {
+	case BytesWritableClassName:
+		return compareBytesWritable, nil
+	case TextClassName:
+		return compareText, nil
+	case IntWritableClassName:
+		return compareInt, nil
+	case LongWritableClassName:
+		return compareLong, nil
+	case NullWritableClassName:
+		return compareNull, nil
+	case BooleanWritableClassName:
+		return compareBoolean, nil
+	case FloatWritableClassName:
+		return compareFloat, nil
+	case DoubleWritableClassName:
+		return compareDouble, nil
+	case VIntWritableClassName:
+		return compareVLong, nil
+	case VLongWritableClassName:
+		return compareVLong, nil
+	default:
+		return nil, fmt.Errorf("Unknown writable class %s", className)
+	}
+}
@@ -0,0 +1,134 @@
+package sequencefile
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Each list is in ascending order, as sorted by Hadoop.
+var rawComparisons = []struct {
+	class  string
+	values []interface{}
+}{
+	{TextClassName, []interface{}{"", "a", "ab", "b", "z", "é"}},
+	{BytesWritableClassName, []interface{}{[]byte{}, []byte{0x00}, []byte{0x01, 0x00}, []byte{0x7f}, []byte{0x80}, []byte{0xff}}},
+	{IntWritableClassName, []interface{}{int32(math.MinInt32), int32(-1), int32(0), int32(1), int32(math.MaxInt32)}},
+	{LongWritableClassName, []interface{}{int64(math.MinInt64), int64(-1), int64(0), int64(1), int64(math.MaxInt64)}},
+	{BooleanWritableClassName, []interface{}{false, true}},
+	{FloatWritableClassName, []interface{}{float32(math.Inf(-1)), float32(-1.5), float32(0), float32(1e-10), float32(math.Inf(1))}},
+	{DoubleWritableClassName, []interface{}{math.Inf(-1), -1.5, 0.0, 1e-300, math.Inf(1)}},
+	{VIntWritableClassName, []interface{}{int32(math.MinInt32), int32(-1000), int32(-1), int32(0), int32(127), int32(1000)}},
+	{VLongWritableClassName, []interface{}{int64(math.MinInt64), int64(-1000), int64(0), int64(1000), int64(math.MaxInt64)}},
+}
+
+func TestRawComparator(t *testing.T) {
+	for _, spec := range rawComparisons {
+		t.Run(spec.class, func(t *testing.T) {
+			write, err := NewWritableWriter(spec.class)
+			require.NoError(t, err)
+			cmp, err := NewRawComparator(spec.class)
+			require.NoError(t, err)
+
+			serialized := make([][]byte, len(spec.values))
+			for i, v := range spec.values {
+				var buf bytes.Buffer
+				require.NoError(t, write(&buf, v))
+				serialized[i] = buf.Bytes()
+			}
+
+			for i, a := range serialized {
+				for j, b := range serialized {
+					msg := fmt.Sprintf("comparing %v and %v", spec.values[i], spec.values[j])
+					switch {
+					case i < j:
+						assert.Negative(t, cmp(a, b), msg)
+					case i > j:
+						assert.Positive(t, cmp(a, b), msg)
+					default:
+						assert.Zero(t, cmp(a, b), msg)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRawComparatorNaN(t *testing.T) {
+	cmp, err := NewRawComparator(DoubleWritableClassName)
+	require.NoError(t, err)
+
+	var nan, one bytes.Buffer
+	require.NoError(t, writeDouble(&nan, math.NaN()))
+	require.NoError(t, writeDouble(&one, 1.0))
+
+	// Like Java, any comparison involving NaN returns 1.
+	assert.Equal(t, 1, cmp(nan.Bytes(), one.Bytes()))
+	assert.Equal(t, 1, cmp(nan.Bytes(), nan.Bytes()))
+	assert.Equal(t, 1, cmp(one.Bytes(), nan.Bytes()))
+}
+
+func TestRawComparatorUnknownClass(t *testing.T) {
+	_, err := NewRawComparator("com.example.Foo")
+	assert.Error(t, err)
+}
+
+func TestRawComparatorMalformed(t *testing.T) {
+	malformed := [][]byte{nil, {0x00}, {0x05, 'a'}, {0x8f}, {0x8c, 0x01}, {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}}
+	for _, spec := range rawComparisons {
+		t.Run(spec.class, func(t *testing.T) {
+			write, err := NewWritableWriter(spec.class)
+			require.NoError(t, err)
+			cmp, err := NewRawComparator(spec.class)
+			require.NoError(t, err)
+
+			values := append([][]byte(nil), malformed...)
+			for _, v := range spec.values {
+				var buf bytes.Buffer
+				require.NoError(t, write(&buf, v))
+				values = append(values, buf.Bytes())
+			}
+
+			// Malformed values shouldn't cause a panic, and the order should
+			// still be consistent.
+			for _, a := range values {
+				for _, b := range values {
+					assert.NotPanics(t, func() { cmp(a, b) })
+					assert.Equal(t, sign(cmp(a, b)), -sign(cmp(b, a)), "comparing %x and %x", a, b)
+					for _, c := range values {
+						if cmp(a, b) <= 0 && cmp(b, c) <= 0 {
+							assert.LessOrEqual(t, cmp(a, c), 0, "comparing %x, %x and %x", a, b, c)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRawComparatorAllocs(t *testing.T) {
+	cmp, err := NewRawComparator(TextClassName)
+	require.NoError(t, err)
+
+	var a, b bytes.Buffer
+	require.NoError(t, writeText(&a, "foo"))
+	require.NoError(t, writeText(&b, "bar"))
+
+	allocs := testing.AllocsPerRun(100, func() { cmp(a.Bytes(), b.Bytes()) })
+	assert.Zero(t, allocs)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,166 @@
+package sequencefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures below generate SequenceFiles with particular keys for tests,
+// all written with assertWrite.
+
+// text serializes a Text.
+func text(s string) []byte {
+	var buf bytes.Buffer
+	writeText(&buf, s)
+	return buf.Bytes()
+}
+
+// longKey serializes a LongWritable.
+func longKey(n int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(n))
+	return b
+}
+
+// openBytes returns a Reader for a serialized SequenceFile, with the header
+// already read.
+func openBytes(t *testing.T, b []byte) *Reader {
+	r := NewReader(bytes.NewReader(b))
+	require.NoError(t, r.ReadHeader())
+	return r
+}
+
+// writeFile writes a SequenceFile to path.
+func writeFile(t *testing.T, path string, cfg *WriterConfig, pairs []writePair) {
+	require.NoError(t, os.WriteFile(path, assertWrite(t, cfg, pairs), 0644))
+}
+
+// writeGroups returns a Reader for a file with the given Text keys, and
+// IntWritable values numbering the records.
+func writeGroups(t *testing.T, keys []string) *Reader {
+	var pairs []writePair
+	for i, k := range keys {
+		pairs = append(pairs, writePair{k, int32(i)})
+	}
+
+	return openBytes(t, assertWrite(t, &WriterConfig{
+		KeyClass:   TextClassName,
+		ValueClass: IntWritableClassName,
+	}, pairs))
+}
+
+// writeLongs returns a file with the given LongWritable keys, and the given
+// Text value for every record, which is also set as the "input" metadata.
+func writeLongs(t *testing.T, keys []int64, value string) *bytes.Reader {
+	var pairs []writePair
+	for _, k := range keys {
+		pairs = append(pairs, writePair{k, value})
+	}
+
+	return bytes.NewReader(assertWrite(t, &WriterConfig{
+		KeyClass:   LongWritableClassName,
+		ValueClass: TextClassName,
+		Metadata:   map[string]string{"input": value},
+	}, pairs))
+}
+
+// writeUnsorted returns a file with n records with random IntWritable keys
+// from a small range, so there are plenty of duplicates, and LongWritable
+// values counting up from start.
+func writeUnsorted(t *testing.T, rng *rand.Rand, n int, start int64) *bytes.Reader {
+	var pairs []writePair
+	for i := 0; i < n; i++ {
+		pairs = append(pairs, writePair{int32(rng.Intn(100) - 50), start + int64(i)})
+	}
+
+	return bytes.NewReader(assertWrite(t, &WriterConfig{
+		KeyClass:         IntWritableClassName,
+		ValueClass:       LongWritableClassName,
+		Compression:      BlockCompression,
+		CompressionCodec: GzipCompression,
+		BlockSize:        500,
+		Metadata:         map[string]string{"foo": "bar"},
+	}, pairs))
+}
+
+// writeUnsortedFile writes n records with random Text keys, some of them
+// repeated, and LongWritable values numbering the records, and returns the
+// keys. The keys are the same for every call with the same n; markerSeed only
+// picks the sync marker.
+func writeUnsortedFile(t *testing.T, path string, compression Compression, markerSeed int64, n int) []string {
+	rng := rand.New(rand.NewSource(1))
+	keys := make([]string, n)
+	var pairs []writePair
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", rng.Intn(n))
+		pairs = append(pairs, writePair{keys[i], int64(i)})
+	}
+
+	writeFile(t, path, withCompression(&WriterConfig{
+		KeyClass:   TextClassName,
+		ValueClass: LongWritableClassName,
+		BlockSize:  500,
+		Metadata:   map[string]string{"foo": "bar"},
+		Rand:       rand.New(rand.NewSource(markerSeed)),
+	}, compression), pairs)
+	return keys
+}
+
+// writeSorted writes a file with LongWritable keys counting up by one or two
+// from -500, with some repeated, and LongWritable values numbering the
+// records. It returns the path and the keys.
+func writeSorted(t *testing.T, compression Compression) (string, []int64) {
+	rng := rand.New(rand.NewSource(1))
+	var keys []int64
+	var pairs []writePair
+	key := int64(-500)
+	for i := 0; i < 2000; i++ {
+		keys = append(keys, key)
+		pairs = append(pairs, writePair{key, int64(i)})
+		key += int64(rng.Intn(3))
+	}
+
+	path := filepath.Join(t.TempDir(), "sorted.sequencefile")
+	writeFile(t, path, withCompression(&WriterConfig{
+		KeyClass:   LongWritableClassName,
+		ValueClass: LongWritableClassName,
+		BlockSize:  300,
+	}, compression), pairs)
+	return path, keys
+}
+
+// writeParts writes a directory of job output, with LongWritable keys counting
+// up from zero across the parts, along with the files that Hadoop adds.
+func writeParts(t *testing.T, dir string, parts, perPart int) {
+	for p := 0; p < parts; p++ {
+		var pairs []writePair
+		for i := 0; i < perPart; i++ {
+			pairs = append(pairs, writePair{int64(p*perPart + i), "foo"})
+		}
+
+		writeFile(t, filepath.Join(dir, fmt.Sprintf("part-r-%05d", p)), &WriterConfig{
+			KeyClass:   LongWritableClassName,
+			ValueClass: TextClassName,
+		}, pairs)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_SUCCESS"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".part-r-00000.crc"), []byte("junk"), 0644))
+}
+
+// withCompression sets the compression for cfg, using gzip.
+func withCompression(cfg *WriterConfig, compression Compression) *WriterConfig {
+	if compression != NoCompression {
+		cfg.Compression = compression
+		cfg.CompressionCodec = GzipCompression
+	}
+
+	return cfg
+}
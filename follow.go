@@ -0,0 +1,138 @@
+package sequencefile
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+const defaultPollInterval = time.Second
+
+// A FollowConfig specifies how a Reader created with Follow waits for new
+// data.
+type FollowConfig struct {
+	// PollInterval is how often to check for new data once the end of the file
+	// is reached. It defaults to one second.
+	PollInterval time.Duration
+
+	// IdleTimeout, if set, causes the Reader to stop once no new data has been
+	// written for that long, treating the file as closed by the writer.
+	IdleTimeout time.Duration
+}
+
+// Follow opens a SequenceFile that is still being written, and returns a
+// Reader that waits for more data at the end of the file instead of stopping,
+// like tail -f. Partially written records and blocks are held until the rest
+// arrives. Follow itself waits for the header to be written, if necessary.
+//
+// The Reader stops, with Scan returning false, when ctx is canceled, when the
+// file is renamed or removed (as writers like Flume do once a file is
+// complete) and everything written to it has been read, or when IdleTimeout
+// passes without any new data. If the Reader stops partway through a record
+// because of ctx or IdleTimeout, the partial record is discarded rather than
+// reported by Err, and Truncated returns true. Once the file has been renamed
+// or removed, though, it's complete, so a partial record at the end is an
+// error. Call Close to close the file once you're done.
+func Follow(ctx context.Context, path string, cfg *FollowConfig) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	fr := &followReader{
+		ctx:        ctx,
+		f:          f,
+		path:       path,
+		info:       info,
+		interval:   defaultPollInterval,
+		lastActive: time.Now(),
+	}
+
+	if cfg != nil {
+		if cfg.PollInterval > 0 {
+			fr.interval = cfg.PollInterval
+		}
+		fr.idleTimeout = cfg.IdleTimeout
+	}
+
+	r := NewReader(bufio.NewReader(fr))
+	r.file = f
+	r.follow = fr
+	err = r.ReadHeader()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// A followReader reads from a file, waiting for more data to be written
+// whenever it reaches the end.
+type followReader struct {
+	ctx         context.Context
+	f           *os.File
+	path        string
+	info        os.FileInfo
+	interval    time.Duration
+	idleTimeout time.Duration
+	lastActive  time.Time
+
+	renamed bool
+	stopped bool
+}
+
+func (fr *followReader) Read(b []byte) (int, error) {
+	for {
+		n, err := fr.f.Read(b)
+		if n > 0 || (err != nil && err != io.EOF) {
+			fr.lastActive = time.Now()
+			return n, err
+		}
+
+		// We've caught up with the writer. If the file was renamed, we've now
+		// read everything that will ever be in it.
+		if fr.renamed || fr.shouldStop() {
+			fr.stopped = true
+			return 0, io.EOF
+		}
+
+		timer := time.NewTimer(fr.interval)
+		select {
+		case <-fr.ctx.Done():
+			timer.Stop()
+			fr.stopped = true
+			return 0, io.EOF
+		case <-timer.C:
+		}
+
+		fr.renamed = fr.wasRenamed()
+	}
+}
+
+func (fr *followReader) shouldStop() bool {
+	if fr.ctx.Err() != nil {
+		return true
+	}
+
+	return fr.idleTimeout > 0 && time.Since(fr.lastActive) >= fr.idleTimeout
+}
+
+// wasRenamed returns true if the path no longer refers to the file we have
+// open.
+func (fr *followReader) wasRenamed() bool {
+	info, err := os.Stat(fr.path)
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(info, fr.info)
+}
@@ -0,0 +1,170 @@
+package sequencefile
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter writes to a file a few bytes at a time, so that the reader sees
+// partial records.
+type slowWriter struct {
+	f *os.File
+}
+
+func (s *slowWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		n := 7
+		if n > len(b) {
+			n = len(b)
+		}
+
+		m, err := s.f.Write(b[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+
+		b = b[n:]
+		time.Sleep(time.Millisecond)
+	}
+
+	return written, nil
+}
+
+func TestFollow(t *testing.T) {
+	compressions := []compressionSpec{
+		{NoCompression, 0},
+		{BlockCompression, GzipCompression},
+	}
+
+	for _, cmp := range compressions {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "test.sequencefile.tmp")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			w, err := NewWriter(&WriterConfig{
+				Writer:           &slowWriter{f},
+				KeyClass:         IntWritableClassName,
+				ValueClass:       TextClassName,
+				Compression:      cmp.compression,
+				CompressionCodec: cmp.codec,
+				BlockSize:        100,
+			})
+			if err != nil {
+				done <- err
+				return
+			}
+
+			for i := 0; i < 50; i++ {
+				if err := w.Append(int32(i), "foo"); err != nil {
+					done <- err
+					return
+				}
+			}
+
+			w.Close()
+			f.Close()
+			done <- os.Rename(path, filepath.Join(dir, "test.sequencefile"))
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		r, err := Follow(ctx, path, &FollowConfig{PollInterval: 5 * time.Millisecond})
+		require.NoError(t, err)
+
+		n := int32(0)
+		for r.Scan() {
+			assert.Equal(t, n, IntWritable(r.Key()))
+			assert.Equal(t, "foo", Text(r.Value()))
+			n++
+		}
+
+		require.NoError(t, <-done)
+		assert.NoError(t, r.Err())
+		assert.NoError(t, ctx.Err(), "the reader should stop once the file is renamed")
+		assert.Equal(t, int32(50), n)
+		r.Close()
+		cancel()
+	}
+}
+
+func TestFollowCancel(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{NoCompression, 0}, 10)
+	path := filepath.Join(t.TempDir(), "test.sequencefile")
+
+	// Leave off the end of the last record.
+	require.NoError(t, os.WriteFile(path, buf[:len(buf)-10], 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := Follow(ctx, path, &FollowConfig{PollInterval: 5 * time.Millisecond})
+	require.NoError(t, err)
+	defer r.Close()
+
+	for i := 0; i < 9; i++ {
+		require.True(t, r.Scan())
+	}
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+	assert.False(t, r.Scan())
+	assert.NoError(t, r.Err())
+	assert.True(t, r.Truncated())
+}
+
+func TestFollowTruncated(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{NoCompression, 0}, 10)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.sequencefile.tmp")
+
+	// Leave off the end of the last record, and then mark the file as complete.
+	require.NoError(t, os.WriteFile(path, buf[:len(buf)-10], 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r, err := Follow(ctx, path, &FollowConfig{PollInterval: 5 * time.Millisecond})
+	require.NoError(t, err)
+	defer r.Close()
+
+	for i := 0; i < 9; i++ {
+		require.True(t, r.Scan())
+	}
+
+	time.AfterFunc(20*time.Millisecond, func() {
+		os.Rename(path, filepath.Join(dir, "test.sequencefile"))
+	})
+	assert.False(t, r.Scan())
+	assert.ErrorIs(t, r.Err(), io.ErrUnexpectedEOF)
+	assert.True(t, r.Truncated())
+	assert.NoError(t, ctx.Err(), "the reader should stop once the file is renamed")
+}
+
+func TestFollowIdleTimeout(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{NoCompression, 0}, 10)
+	path := filepath.Join(t.TempDir(), "test.sequencefile")
+	require.NoError(t, os.WriteFile(path, buf, 0644))
+
+	r, err := Follow(context.Background(), path, &FollowConfig{
+		PollInterval: 5 * time.Millisecond,
+		IdleTimeout:  20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer r.Close()
+
+	n := 0
+	for r.Scan() {
+		n++
+	}
+
+	assert.NoError(t, r.Err())
+	assert.False(t, r.Truncated())
+	assert.Equal(t, 10, n)
+}
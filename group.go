@@ -0,0 +1,124 @@
+package sequencefile
+
+// A GroupIterator iterates over the records of a sorted SequenceFile grouped
+// by key, like the input to a Hadoop reducer: NextKey advances to each
+// distinct key, and NextValue then iterates over the values for it. Values are
+// read from the Reader as they are requested, so groups of any size can be
+// processed without holding them in memory.
+//
+//	g, err := sequencefile.Grouped(r)
+//	for g.NextKey() {
+//		for g.NextValue() {
+//			// Do something with g.Key() and g.Value()
+//		}
+//	}
+//
+// With a custom grouping comparator, records with keys that differ but compare
+// as equal are grouped together, which is how secondary sorting works in
+// Hadoop. In that case, CurrentKey returns the full key of each record.
+type GroupIterator struct {
+	r   recordSource
+	cmp RawComparator
+
+	key     []byte
+	inGroup bool
+	done    bool
+
+	// If pending is set, the Reader is at the first record of the next group,
+	// which hasn't been returned yet.
+	pending bool
+}
+
+// Grouped returns a GroupIterator over the records from r, which must be
+// sorted by key. Keys are compared with the RawComparator for the key class.
+func Grouped(r *Reader) (*GroupIterator, error) {
+	cmp, err := NewRawComparator(r.Header.KeyClassName)
+	if err != nil {
+		return nil, err
+	}
+
+	return GroupedComparator(r, cmp), nil
+}
+
+// GroupedComparator returns a GroupIterator over the records from r, grouping
+// together consecutive records with keys that cmp considers equal.
+func GroupedComparator(r *Reader, cmp RawComparator) *GroupIterator {
+	return &GroupIterator{r: r, cmp: cmp}
+}
+
+// NextKey advances to the next group, skipping any values remaining in the
+// current one. It returns false once there are no more records, or if there is
+// an error.
+func (g *GroupIterator) NextKey() bool {
+	for g.inGroup {
+		g.NextValue()
+	}
+
+	if g.done {
+		return false
+	}
+
+	if !g.pending {
+		if !g.r.Scan() {
+			g.done = true
+			return false
+		}
+
+		g.pending = true
+	}
+
+	g.key = append(g.key[:0], g.r.Key()...)
+	g.inGroup = true
+	return true
+}
+
+// NextValue advances to the next record in the current group, and returns
+// false once there are no more.
+func (g *GroupIterator) NextValue() bool {
+	if !g.inGroup {
+		return false
+	}
+
+	if g.pending {
+		g.pending = false
+		return true
+	}
+
+	if !g.r.Scan() {
+		g.inGroup = false
+		g.done = true
+		return false
+	}
+
+	if g.cmp(g.r.Key(), g.key) != 0 {
+		g.inGroup = false
+		g.pending = true
+		return false
+	}
+
+	return true
+}
+
+// Key returns the key of the current group, which is the key of its first
+// record. The byte slice will be reused after the next call to NextKey.
+func (g *GroupIterator) Key() []byte {
+	return g.key
+}
+
+// CurrentKey returns the key of the current record, which may differ from Key
+// if a custom grouping comparator is used. The byte slice will be reused after
+// the next call to NextValue.
+func (g *GroupIterator) CurrentKey() []byte {
+	return g.r.Key()
+}
+
+// Value returns the value of the current record. The byte slice will be
+// reused after the next call to NextValue.
+func (g *GroupIterator) Value() []byte {
+	return g.r.Value()
+}
+
+// Err returns the first error reached while reading.
+func (g *GroupIterator) Err() error {
+	return g.r.Err()
+}
@@ -0,0 +1,83 @@
+package sequencefile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrouped(t *testing.T) {
+	r := writeGroups(t, []string{"a", "a", "a", "b", "c", "c"})
+	g, err := Grouped(r)
+	require.NoError(t, err)
+
+	groups := make(map[string][]int32)
+	var order []string
+	for g.NextKey() {
+		key := Text(g.Key())
+		order = append(order, key)
+		for g.NextValue() {
+			groups[key] = append(groups[key], IntWritable(g.Value()))
+		}
+
+		assert.False(t, g.NextValue(), "NextValue should keep returning false at the end of a group")
+	}
+
+	require.NoError(t, g.Err())
+	assert.False(t, g.NextKey())
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+	assert.Equal(t, map[string][]int32{"a": {0, 1, 2}, "b": {3}, "c": {4, 5}}, groups)
+}
+
+func TestGroupedSkipValues(t *testing.T) {
+	r := writeGroups(t, []string{"a", "a", "a", "b", "b", "c"})
+	g, err := Grouped(r)
+	require.NoError(t, err)
+
+	var firsts []int32
+	for i := 0; g.NextKey(); i++ {
+		// Only read the first value of every other group.
+		if i%2 == 0 {
+			require.True(t, g.NextValue())
+			firsts = append(firsts, IntWritable(g.Value()))
+		}
+	}
+
+	require.NoError(t, g.Err())
+	assert.Equal(t, []int32{0, 5}, firsts)
+}
+
+func TestGroupedComparator(t *testing.T) {
+	// Group by the part of the key before the '#', as with secondary sort.
+	cmp := func(a, b []byte) int {
+		a, b = []byte(Text(a)), []byte(Text(b))
+		a, _, _ = bytes.Cut(a, []byte("#"))
+		b, _, _ = bytes.Cut(b, []byte("#"))
+		return bytes.Compare(a, b)
+	}
+
+	r := writeGroups(t, []string{"a#1", "a#2", "b#1", "b#3", "b#4"})
+	g := GroupedComparator(r, cmp)
+
+	var keys, currentKeys []string
+	for g.NextKey() {
+		keys = append(keys, Text(g.Key()))
+		for g.NextValue() {
+			currentKeys = append(currentKeys, Text(g.CurrentKey()))
+		}
+	}
+
+	require.NoError(t, g.Err())
+	assert.Equal(t, []string{"a#1", "b#1"}, keys)
+	assert.Equal(t, []string{"a#1", "a#2", "b#1", "b#3", "b#4"}, currentKeys)
+}
+
+func TestGroupedEmpty(t *testing.T) {
+	g, err := Grouped(writeGroups(t, nil))
+	require.NoError(t, err)
+	assert.False(t, g.NextKey())
+	assert.False(t, g.NextValue())
+	require.NoError(t, g.Err())
+}
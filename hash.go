@@ -0,0 +1,116 @@
+package sequencefile
+
+import (
+	"fmt"
+	"math"
+)
+
+// A WritableHash computes the hash code of a serialized Writable, matching the
+// result of its hashCode method in Java. This is what Hadoop's HashPartitioner
+// uses to assign keys to partitions.
+type WritableHash func([]byte) int32
+
+// hashBytes implements WritableComparator.hashBytes, which is used for Text
+// and BytesWritable.
+func hashBytes(b []byte) int32 {
+	hash := int32(1)
+	for _, c := range b {
+		hash = 31*hash + int32(int8(c))
+	}
+
+	return hash
+}
+
+func hashText(b []byte) int32 {
+	return hashBytes(textBytes(b))
+}
+
+func hashBytesWritable(b []byte) int32 {
+	return hashBytes(BytesWritable(b))
+}
+
+func hashInt(b []byte) int32 {
+	return IntWritable(b)
+}
+
+func hashLongWritable(b []byte) int32 {
+	// Unlike Long.hashCode, LongWritable just truncates the value.
+	return int32(LongWritable(b))
+}
+
+func hashNull(b []byte) int32 {
+	return 0
+}
+
+func hashBoolean(b []byte) int32 {
+	// Yes, really.
+	if BooleanWritable(b) {
+		return 0
+	}
+
+	return 1
+}
+
+func hashFloat(b []byte) int32 {
+	// Float.floatToIntBits collapses all NaNs to a canonical one.
+	f := FloatWritable(b)
+	if f != f {
+		return 0x7fc00000
+	}
+
+	return int32(math.Float32bits(f))
+}
+
+func hashDouble(b []byte) int32 {
+	// Like Double.doubleToLongBits, with a canonical NaN, truncated.
+	d := DoubleWritable(b)
+	if d != d {
+		return 0
+	}
+
+	return int32(math.Float64bits(d))
+}
+
+func hashVInt(b []byte) int32 {
+	return VIntWritable(b)
+}
+
+func hashVLong(b []byte) int32 {
+	return int32(VLongWritable(b))
+}
+
+// NewWritableHash gets a WritableHash for a given Hadoop class name.
+func NewWritableHash(className string) (WritableHash, error) {
+	switch className {
+	case BytesWritableClassName:
+		return hashBytesWritable, nil
+	case TextClassName:
+		return hashText, nil
+	case IntWritableClassName:
+		return hashInt, nil
+	case LongWritableClassName:
+		return hashLongWritable, nil
+	case NullWritableClassName:
+		return hashNull, nil
+	case BooleanWritableClassName:
+		return hashBoolean, nil
+	case FloatWritableClassName:
+		return hashFloat, nil
+	case DoubleWritableClassName:
+		return hashDouble, nil
+	case VIntWritableClassName:
+		return hashVInt, nil
+	case VLongWritableClassName:
+		return hashVLong, nil
+	default:
+		return nil, fmt.Errorf("Unknown writable class %s", className)
+	}
+}
+
+// HashPartition returns the partition a hash code belongs to, out of n, in
+// the same way as Hadoop's HashPartitioner:
+//
+//	(key.hashCode() & Integer.MAX_VALUE) % numReduceTasks
+func HashPartition(hash int32, n int) int {
+	return int(hash&math.MaxInt32) % n
+}
@@ -0,0 +1,108 @@
+package sequencefile
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// To generate the values used in these tests:
+// scala> new org.apache.hadoop.io.Text("hello").hashCode
+
+var hashCodes = []struct {
+	class    string
+	value    interface{}
+	expected int32
+}{
+	{TextClassName, "", 1},
+	{TextClassName, "abc", 126145},
+	{TextClassName, "hello", 127791473},
+	{TextClassName, "café", 123043501},
+	{BytesWritableClassName, []byte("hello"), 127791473},
+	{IntWritableClassName, int32(-7), -7},
+	{LongWritableClassName, int64(1<<32 + 5), 5},
+	{LongWritableClassName, int64(-1), -1},
+	{LongWritableClassName, int64(-1 << 32), 0},
+	{NullWritableClassName, nil, 0},
+	{BooleanWritableClassName, true, 0},
+	{BooleanWritableClassName, false, 1},
+	{FloatWritableClassName, float32(1.0), 1065353216},
+	{FloatWritableClassName, float32(math.NaN()), 0x7fc00000},
+	{DoubleWritableClassName, 0.1, -1717986918},
+	{VIntWritableClassName, int32(1000), 1000},
+	{VLongWritableClassName, int64(1<<32 + 5), 5},
+	{VLongWritableClassName, int64(-1), -1},
+}
+
+func TestWritableHash(t *testing.T) {
+	for _, spec := range hashCodes {
+		t.Run(fmt.Sprintf("%s/%v", spec.class, spec.value), func(t *testing.T) {
+			write, err := NewWritableWriter(spec.class)
+			require.NoError(t, err)
+			hash, err := NewWritableHash(spec.class)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, write(&buf, spec.value))
+			assert.Equal(t, spec.expected, hash(buf.Bytes()), "the hash code should match Java")
+		})
+	}
+}
+
+func TestHashPartition(t *testing.T) {
+	assert.Equal(t, 3, HashPartition(123, 10))
+	assert.Equal(t, 7, HashPartition(-1, 10), "negative hashes should be masked, not negated")
+	assert.Equal(t, 0, HashPartition(math.MinInt32, 10))
+}
+
+func TestPartitionedWriter(t *testing.T) {
+	bufs := make([]bytes.Buffer, 3)
+	writers := make([]*Writer, len(bufs))
+	for i := range bufs {
+		w, err := NewWriter(&WriterConfig{
+			Writer:     &bufs[i],
+			KeyClass:   TextClassName,
+			ValueClass: IntWritableClassName,
+		})
+		require.NoError(t, err)
+		writers[i] = w
+	}
+
+	pw, err := NewPartitionedWriter(writers)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, pw.Append(fmt.Sprintf("key-%d", i), int32(i)))
+	}
+	require.NoError(t, pw.Close())
+
+	total := 0
+	for i := range bufs {
+		r := NewReader(&bufs[i])
+		require.NoError(t, r.ReadHeader())
+
+		for r.Scan() {
+			total++
+			hash := hashBytes([]byte(Text(r.Key())))
+			assert.Equal(t, i, HashPartition(hash, len(bufs)), "each key should be in its partition")
+		}
+		require.NoError(t, r.Err())
+	}
+
+	assert.Equal(t, 100, total)
+}
+
+func TestPartitionedWriterMismatchedKeys(t *testing.T) {
+	var a, b bytes.Buffer
+	wa, err := NewWriter(&WriterConfig{Writer: &a, KeyClass: TextClassName})
+	require.NoError(t, err)
+	wb, err := NewWriter(&WriterConfig{Writer: &b, KeyClass: LongWritableClassName})
+	require.NoError(t, err)
+
+	_, err = NewPartitionedWriter([]*Writer{wa, wb})
+	assert.Error(t, err)
+}
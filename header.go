@@ -107,6 +107,8 @@ func (r *Reader) ReadHeader() error {
 	r.Header.SyncMarker = string(marker)
 	r.syncMarkerBytes = make([]byte, SyncSize)
 	copy(r.syncMarkerBytes, marker)
+	r.headerEnd = r.in.pos
+	r.start = r.headerEnd
 
 	return nil
 }
@@ -143,7 +145,7 @@ func (r *Reader) readMetadata() error {
 }
 
 func (r *Reader) readString() (string, error) {
-	length, err := ReadVInt(r.reader)
+	length, err := ReadVInt(&r.in)
 	if err != nil {
 		return "", err
 	}
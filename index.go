@@ -0,0 +1,426 @@
+package sequencefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/colinmarc/sequencefile/internal/pending"
+)
+
+const (
+	indexMagic           = "SEQIDX"
+	indexVersion    byte = 1
+	indexFlagKeys   byte = 1
+	indexFileExt         = ".idx"
+	indexTmpFileExt      = ".idx.tmp"
+)
+
+// IndexOptions specifies what BuildIndex includes in an index.
+type IndexOptions struct {
+	// Keys, if set, causes the index to include a table of key hashes, so that
+	// records can be found by key with IndexedReader.Find. This adds roughly
+	// five bytes per record to the index.
+	Keys bool
+}
+
+// IndexPath returns the path of the sidecar index for the SequenceFile at
+// path. The index is stored alongside the file, in a hidden file, so that
+// it's skipped by OpenDir and by Hadoop.
+func IndexPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+indexFileExt)
+}
+
+// An indexPoint is a position in a file that the Reader can seek to: the
+// first record, and the first record after each sync marker.
+type indexPoint struct {
+	offset      int64
+	firstRecord int64
+}
+
+type keyHash struct {
+	hash  uint32
+	point int
+}
+
+// An offsetIndex is the contents of a sidecar index.
+type offsetIndex struct {
+	size       int64
+	headerCRC  uint32
+	syncMarker []byte
+	records    int64
+	points     []indexPoint
+	hasKeys    bool
+	keys       []keyHash
+}
+
+// BuildIndex scans the SequenceFile at path, and writes a sidecar index for
+// it to IndexPath(path). The index records the offset of every sync marker and
+// the number of records before it, and optionally a hash of every key, which
+// allows an IndexedReader to seek straight to a record by number or by key.
+//
+// The index also records the size of the file and a checksum of its header,
+// so that OpenIndexed can reject an index that is out of date.
+func BuildIndex(path string, opts *IndexOptions) error {
+	r, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	crc, err := headerCRC(path, r.headerEnd)
+	if err != nil {
+		return err
+	}
+
+	ix := &offsetIndex{
+		headerCRC:  crc,
+		syncMarker: r.syncMarkerBytes,
+		hasKeys:    opts != nil && opts.Keys,
+	}
+
+	syncs := r.stats.SyncMarkers
+	for r.Scan() {
+		// Every sync marker starts a new run of records.
+		if ix.records == 0 || r.stats.SyncMarkers != syncs {
+			ix.points = append(ix.points, indexPoint{r.Offset(), ix.records})
+			syncs = r.stats.SyncMarkers
+		}
+
+		if ix.hasKeys {
+			ix.keys = append(ix.keys, keyHash{hashKey(r.Key()), len(ix.points) - 1})
+		}
+
+		ix.records++
+	}
+
+	if r.Err() != nil {
+		return r.Err()
+	}
+
+	ix.size = r.in.pos
+	ix.sortKeys()
+
+	f, err := pending.Create(
+		filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+indexTmpFileExt),
+		IndexPath(path))
+	if err != nil {
+		return err
+	}
+
+	err = ix.write(f)
+	if err != nil {
+		f.Abort()
+		return err
+	}
+
+	return f.Commit()
+}
+
+// sortKeys sorts the key hashes, and removes duplicates, which come from
+// records in the same run with the same key (or a colliding one).
+func (ix *offsetIndex) sortKeys() {
+	sort.Slice(ix.keys, func(i, j int) bool {
+		a, b := ix.keys[i], ix.keys[j]
+		return a.hash < b.hash || (a.hash == b.hash && a.point < b.point)
+	})
+
+	deduped := ix.keys[:0]
+	for i, k := range ix.keys {
+		if i == 0 || k != ix.keys[i-1] {
+			deduped = append(deduped, k)
+		}
+	}
+
+	ix.keys = deduped
+}
+
+func headerCRC(path string, headerEnd int64) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	h := crc32.NewIEEE()
+	if _, err := io.CopyN(h, f, headerEnd); err != nil {
+		return 0, err
+	}
+
+	return h.Sum32(), nil
+}
+
+func hashKey(key []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(key)
+	return h.Sum32()
+}
+
+// write serializes the index. Everything but the sync marker and the header
+// checksum is written as VInts, with offsets, record numbers and key hashes
+// delta-encoded.
+func (ix *offsetIndex) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(indexMagic)
+	bw.WriteByte(indexVersion)
+
+	var flags byte
+	if ix.hasKeys {
+		flags |= indexFlagKeys
+	}
+	bw.WriteByte(flags)
+
+	WriteVInt(bw, ix.size)
+	binary.Write(bw, binary.BigEndian, ix.headerCRC)
+	bw.Write(ix.syncMarker)
+	WriteVInt(bw, ix.records)
+
+	WriteVInt(bw, int64(len(ix.points)))
+	var prev indexPoint
+	for _, p := range ix.points {
+		WriteVInt(bw, p.offset-prev.offset)
+		WriteVInt(bw, p.firstRecord-prev.firstRecord)
+		prev = p
+	}
+
+	if ix.hasKeys {
+		WriteVInt(bw, int64(len(ix.keys)))
+		var prevHash uint32
+		for _, k := range ix.keys {
+			WriteVInt(bw, int64(k.hash-prevHash))
+			WriteVInt(bw, int64(k.point))
+			prevHash = k.hash
+		}
+	}
+
+	return bw.Flush()
+}
+
+func readIndex(r io.Reader) (*offsetIndex, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(indexMagic)+2)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	} else if string(magic[:len(indexMagic)]) != indexMagic {
+		return nil, errors.New("sequencefile: not an index file")
+	} else if magic[len(indexMagic)] != indexVersion {
+		return nil, fmt.Errorf("sequencefile: unsupported index version: %d", magic[len(indexMagic)])
+	}
+
+	ix := &offsetIndex{hasKeys: magic[len(indexMagic)+1]&indexFlagKeys != 0}
+	var err error
+	if ix.size, err = ReadVInt(br); err != nil {
+		return nil, err
+	}
+
+	fixed := make([]byte, 4+SyncSize)
+	if _, err := io.ReadFull(br, fixed); err != nil {
+		return nil, err
+	}
+
+	ix.headerCRC = binary.BigEndian.Uint32(fixed)
+	ix.syncMarker = fixed[4:]
+	if ix.records, err = ReadVInt(br); err != nil {
+		return nil, err
+	}
+
+	n, err := ReadVInt(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var prev indexPoint
+	for i := int64(0); i < n; i++ {
+		offset, err := ReadVInt(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		first, err := ReadVInt(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		prev = indexPoint{prev.offset + offset, prev.firstRecord + first}
+		ix.points = append(ix.points, prev)
+	}
+
+	if ix.hasKeys {
+		n, err := ReadVInt(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		var prevHash uint32
+		for i := int64(0); i < n; i++ {
+			delta, err := ReadVInt(br)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			point, err := ReadVInt(br)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			} else if point < 0 || point >= int64(len(ix.points)) {
+				return nil, errors.New("sequencefile: invalid index")
+			}
+
+			prevHash += uint32(delta)
+			ix.keys = append(ix.keys, keyHash{prevHash, int(point)})
+		}
+	}
+
+	return ix, nil
+}
+
+// An IndexedReader is a Reader for a SequenceFile with a sidecar index built
+// by BuildIndex, which allows seeking directly to a record by its number or
+// its key.
+type IndexedReader struct {
+	*Reader
+	index *offsetIndex
+}
+
+// OpenIndexed opens a SequenceFile on disk, like Open, along with its sidecar
+// index. It returns an error if the index doesn't exist, or if it is out of
+// date, meaning that the size, header or sync marker of the file don't match
+// what they were when the index was built.
+func OpenIndexed(path string) (*IndexedReader, error) {
+	f, err := os.Open(IndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ix, err := readIndex(f)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ix.check(path, r); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return &IndexedReader{Reader: r, index: ix}, nil
+}
+
+func (ix *offsetIndex) check(path string, r *Reader) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Size() != ix.size {
+		return fmt.Errorf("sequencefile: stale index: the file is %d bytes, but the index is for %d", info.Size(), ix.size)
+	} else if !bytes.Equal(r.syncMarkerBytes, ix.syncMarker) {
+		return errors.New("sequencefile: stale index: the sync marker doesn't match")
+	}
+
+	crc, err := headerCRC(path, r.headerEnd)
+	if err != nil {
+		return err
+	} else if crc != ix.headerCRC {
+		return errors.New("sequencefile: stale index: the header doesn't match")
+	}
+
+	return nil
+}
+
+// Records returns the total number of records in the file.
+func (r *IndexedReader) Records() int64 {
+	return r.index.records
+}
+
+// SeekRecord positions the Reader at the nth record in the file, counting
+// from zero, so that the next call to Scan returns it. It returns io.EOF if
+// there are n or fewer records.
+func (r *IndexedReader) SeekRecord(n int64) error {
+	if n < 0 || n >= r.index.records {
+		return io.EOF
+	}
+
+	i := sort.Search(len(r.index.points), func(i int) bool {
+		return r.index.points[i].firstRecord > n
+	}) - 1
+
+	p := r.index.points[i]
+	if _, err := r.Seek(p.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	for skip := n - p.firstRecord; skip >= 0; skip-- {
+		if !r.scan() {
+			if r.err != nil {
+				return r.err
+			}
+
+			return io.ErrUnexpectedEOF
+		}
+	}
+
+	r.unscan()
+	return nil
+}
+
+// Find positions the Reader at the first record with the given serialized key,
+// so that the next call to Scan returns it, and returns true. If there is no
+// such record, it returns false. The index must have been built with Keys
+// set in IndexOptions.
+func (r *IndexedReader) Find(key []byte) (bool, error) {
+	if !r.index.hasKeys {
+		return false, errors.New("sequencefile: the index doesn't include keys")
+	}
+
+	h := hashKey(key)
+	keys := r.index.keys
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i].hash >= h
+	})
+
+	// Check every run that has a key with the same hash, in order.
+	for ; i < len(keys) && keys[i].hash == h; i++ {
+		found, err := r.findInRun(keys[i].point, key)
+		if err != nil || found {
+			return found, err
+		}
+	}
+
+	return false, nil
+}
+
+// findInRun scans the records in the run starting at the given point, looking
+// for the key.
+func (r *IndexedReader) findInRun(point int, key []byte) (bool, error) {
+	p := r.index.points[point]
+	end := r.index.records
+	if point+1 < len(r.index.points) {
+		end = r.index.points[point+1].firstRecord
+	}
+
+	if _, err := r.Seek(p.offset, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	for n := p.firstRecord; n < end && r.scan(); n++ {
+		if bytes.Equal(r.key, key) {
+			r.unscan()
+			return true, nil
+		}
+	}
+
+	return false, r.err
+}
@@ -0,0 +1,115 @@
+package sequencefile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedReader(t *testing.T) {
+	for _, compression := range []Compression{NoCompression, RecordCompression, BlockCompression} {
+		t.Run(compression.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data")
+			keys := writeUnsortedFile(t, path, compression, 1, 2000)
+			require.NoError(t, BuildIndex(path, &IndexOptions{Keys: true}))
+
+			r, err := OpenIndexed(path)
+			require.NoError(t, err)
+			defer r.Close()
+			assert.Equal(t, int64(2000), r.Records())
+			assert.Greater(t, len(r.index.points), 10)
+
+			for _, n := range []int64{1999, 0, 1000, 1, 517, 518} {
+				require.NoError(t, r.SeekRecord(n))
+				require.True(t, r.Scan())
+				assert.Equal(t, n, LongWritable(r.Value()))
+				assert.Equal(t, keys[n], Text(r.Key()))
+
+				// Scanning should continue from there.
+				if n < 1999 {
+					require.True(t, r.Scan())
+					assert.Equal(t, n+1, LongWritable(r.Value()))
+				}
+			}
+
+			assert.Equal(t, io.EOF, r.SeekRecord(2000))
+
+			first := make(map[string]int64)
+			for i, k := range keys {
+				if _, ok := first[k]; !ok {
+					first[k] = int64(i)
+				}
+			}
+
+			for _, i := range []int{0, 1999, 1234, 42} {
+				found, err := r.Find(text(keys[i]))
+				require.NoError(t, err)
+				require.True(t, found, keys[i])
+				require.True(t, r.Scan())
+				assert.Equal(t, first[keys[i]], LongWritable(r.Value()), "the first record with the key should be found")
+			}
+
+			found, err := r.Find(text("missing"))
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestIndexWithoutKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	writeUnsortedFile(t, path, NoCompression, 1, 100)
+	require.NoError(t, BuildIndex(path, nil))
+
+	r, err := OpenIndexed(path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.NoError(t, r.SeekRecord(50))
+	require.True(t, r.Scan())
+	assert.Equal(t, int64(50), LongWritable(r.Value()))
+
+	_, err = r.Find(text("key-1"))
+	assert.Error(t, err)
+}
+
+func TestStaleIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	writeUnsortedFile(t, path, BlockCompression, 1, 100)
+	require.NoError(t, BuildIndex(path, nil))
+
+	// The same records, with a different sync marker.
+	writeUnsortedFile(t, path, BlockCompression, 2, 100)
+	_, err := OpenIndexed(path)
+	assert.Error(t, err)
+
+	// A different size.
+	writeUnsortedFile(t, path, BlockCompression, 1, 101)
+	_, err = OpenIndexed(path)
+	assert.Error(t, err)
+
+	// A different header, with the same size and sync marker.
+	writeUnsortedFile(t, path, BlockCompression, 1, 100)
+	_, err = OpenIndexed(path)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	i := len("SEQ") + 1
+	for b[i] != 'f' || b[i+1] != 'o' || b[i+2] != 'o' {
+		i++
+	}
+	b[i] = 'g'
+	require.NoError(t, os.WriteFile(path, b, 0666))
+	_, err = OpenIndexed(path)
+	assert.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "there should be no temporary files left")
+}
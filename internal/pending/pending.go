@@ -0,0 +1,110 @@
+// Package pending implements files that are written under a temporary name,
+// and only moved to their final path once they're complete, so that readers
+// never see a partially-written file.
+package pending
+
+import (
+	"bufio"
+	"os"
+)
+
+// A File is a buffered file written under a temporary name. Nothing appears at
+// its final path until Commit is called.
+type File struct {
+	*bufio.Writer
+	f    *os.File
+	tmp  string
+	path string
+	err  error
+
+	closed bool
+	done   bool
+}
+
+// Create creates a new file at tmp, which must not already exist, to be moved
+// to path once it's committed.
+func Create(tmp, path string) (*File, error) {
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Writer: bufio.NewWriter(f), f: f, tmp: tmp, path: path}, nil
+}
+
+// Path returns the final path of the file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Close flushes the file and syncs it to disk before closing it, so that it's
+// safe to move into place, but leaves it under its temporary name. Calling
+// Close again returns the same result.
+func (f *File) Close() error {
+	if f.closed {
+		return f.err
+	}
+
+	f.closed = true
+	f.err = f.Flush()
+	if f.err == nil {
+		f.err = f.f.Sync()
+	}
+
+	if err := f.f.Close(); f.err == nil {
+		f.err = err
+	}
+
+	return f.err
+}
+
+// Commit closes the file, if it isn't already, and renames it to its final
+// path, replacing anything already there. If that fails, the temporary file
+// is removed.
+func (f *File) Commit() error {
+	return f.commit(os.Rename)
+}
+
+// CommitNew is like Commit, but fails if something already exists at the
+// final path, rather than replacing it.
+func (f *File) CommitNew() error {
+	// Unlike renaming, linking fails if the destination exists.
+	return f.commit(func(tmp, path string) error {
+		err := os.Link(tmp, path)
+		if err == nil {
+			os.Remove(tmp)
+		}
+
+		return err
+	})
+}
+
+func (f *File) commit(move func(tmp, path string) error) error {
+	if f.done {
+		return nil
+	}
+
+	f.done = true
+	err := f.Close()
+	if err == nil {
+		err = move(f.tmp, f.path)
+	}
+
+	if err != nil {
+		os.Remove(f.tmp)
+	}
+
+	return err
+}
+
+// Abort closes and removes the temporary file, unless it was already
+// committed.
+func (f *File) Abort() {
+	if f.done {
+		return
+	}
+
+	f.done = true
+	f.Close()
+	os.Remove(f.tmp)
+}
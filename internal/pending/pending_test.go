@@ -0,0 +1,71 @@
+package pending
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func create(t *testing.T, dir, name, contents string) *File {
+	f, err := Create(filepath.Join(dir, "."+name+".tmp"), filepath.Join(dir, name))
+	require.NoError(t, err)
+
+	_, err = f.WriteString(contents)
+	require.NoError(t, err)
+	return f
+}
+
+func assertFiles(t *testing.T, dir string, expected map[string]string) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	found := make(map[string]string)
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		found[e.Name()] = string(b)
+	}
+
+	assert.Equal(t, expected, found)
+}
+
+func TestCommit(t *testing.T) {
+	dir := t.TempDir()
+	f := create(t, dir, "foo", "bar")
+	assertFiles(t, dir, map[string]string{".foo.tmp": ""})
+
+	require.NoError(t, f.Close())
+	require.NoError(t, f.Close())
+	assertFiles(t, dir, map[string]string{".foo.tmp": "bar"})
+
+	require.NoError(t, f.Commit())
+	assertFiles(t, dir, map[string]string{"foo": "bar"})
+
+	// Commit replaces existing files.
+	require.NoError(t, create(t, dir, "foo", "baz").Commit())
+	assertFiles(t, dir, map[string]string{"foo": "baz"})
+}
+
+func TestCommitNew(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, create(t, dir, "foo", "bar").CommitNew())
+	assertFiles(t, dir, map[string]string{"foo": "bar"})
+
+	assert.Error(t, create(t, dir, "foo", "baz").CommitNew())
+	assertFiles(t, dir, map[string]string{"foo": "bar"})
+}
+
+func TestAbort(t *testing.T) {
+	dir := t.TempDir()
+	create(t, dir, "foo", "bar").Abort()
+	assertFiles(t, dir, map[string]string{})
+
+	// Aborting after committing does nothing.
+	f := create(t, dir, "foo", "bar")
+	require.NoError(t, f.Commit())
+	f.Abort()
+	assertFiles(t, dir, map[string]string{"foo": "bar"})
+}
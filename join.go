@@ -0,0 +1,229 @@
+package sequencefile
+
+import (
+	"errors"
+	"fmt"
+)
+
+// JoinType specifies which keys a Join returns.
+type JoinType int
+
+const (
+	// InnerJoin returns only keys present in every input.
+	InnerJoin JoinType = iota
+
+	// LeftJoin returns every key in the first input.
+	LeftJoin
+
+	// OuterJoin returns every key in any input.
+	OuterJoin
+)
+
+// A Join does a merge join of two or more SequenceFiles sorted by key, like
+// Hadoop's CompositeInputFormat. Each call to Scan advances to the next
+// combination of records with the same key, one from each input.
+//
+// If a key appears more than once in an input, the result is the cross
+// product of the matching records, so every combination is returned. To do
+// that, the values for the current key are held in memory, but only for one
+// key at a time.
+//
+// The inputs are checked as they are read, like with Merge, and Scan stops
+// with an error at the first key that is out of order.
+type Join struct {
+	joinType JoinType
+	cmp      RawComparator
+	groups   []*GroupIterator
+	started  bool
+	done     bool
+
+	// Whether each input has a current group, and its key.
+	has  []bool
+	keys [][]byte
+
+	// Whether each input has the current key, its values for it, and the
+	// position in the cross product.
+	key     []byte
+	found   []bool
+	matches [][][]byte
+	pos     []int
+	values  [][]byte
+}
+
+// NewJoin returns a Join over the given Readers, which must all be sorted by
+// key, and have the same key class. Keys are compared with the RawComparator
+// for that class.
+func NewJoin(joinType JoinType, readers ...*Reader) (*Join, error) {
+	if len(readers) < 2 {
+		return nil, errors.New("sequencefile: a join needs at least two inputs")
+	}
+
+	keyClass := readers[0].Header.KeyClassName
+	for _, r := range readers[1:] {
+		if r.Header.KeyClassName != keyClass {
+			return nil, fmt.Errorf("sequencefile: mismatched key class: %s != %s", r.Header.KeyClassName, keyClass)
+		}
+	}
+
+	cmp, err := NewRawComparator(keyClass)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJoinComparator(joinType, cmp, readers...), nil
+}
+
+// NewJoinComparator returns a Join over the given Readers, which must all be
+// sorted in the order defined by cmp. Keys are matched if cmp considers them
+// equal.
+func NewJoinComparator(joinType JoinType, cmp RawComparator, readers ...*Reader) *Join {
+	j := &Join{
+		joinType: joinType,
+		cmp:      cmp,
+		groups:   make([]*GroupIterator, len(readers)),
+		has:      make([]bool, len(readers)),
+		keys:     make([][]byte, len(readers)),
+		found:    make([]bool, len(readers)),
+		matches:  make([][][]byte, len(readers)),
+		pos:      make([]int, len(readers)),
+		values:   make([][]byte, len(readers)),
+	}
+
+	for i, r := range readers {
+		src := &sortedSource{Reader: r, cmp: cmp, index: i}
+		j.groups[i] = &GroupIterator{r: src, cmp: cmp}
+	}
+
+	return j
+}
+
+// Scan advances to the next combination of records, which can then be
+// obtained by calling Key and Values. If there are no more, or there is an
+// error, Scan returns false.
+func (j *Join) Scan() bool {
+	if j.done {
+		return false
+	} else if !j.started {
+		j.started = true
+		for i := range j.groups {
+			j.advance(i)
+		}
+	} else if j.nextCombination() {
+		return true
+	}
+
+	for j.Err() == nil {
+		// Find the smallest key, and which inputs have it.
+		min := -1
+		for i, has := range j.has {
+			if has && (min < 0 || j.cmp(j.keys[i], j.keys[min]) < 0) {
+				min = i
+			}
+		}
+
+		if min < 0 {
+			break
+		}
+
+		matched := 0
+		for i, has := range j.has {
+			j.found[i] = has && j.cmp(j.keys[i], j.keys[min]) == 0
+			j.matches[i] = j.matches[i][:0]
+			if j.found[i] {
+				matched++
+			}
+		}
+
+		j.key = append(j.key[:0], j.keys[min]...)
+		include := j.joinType == OuterJoin ||
+			(j.joinType == LeftJoin && j.found[0]) ||
+			(j.joinType == InnerJoin && matched == len(j.groups))
+
+		// Buffer the values for the key, or skip them, and move on to the next
+		// group in each matching input.
+		for i, found := range j.found {
+			if !found {
+				continue
+			}
+
+			for include && j.groups[i].NextValue() {
+				j.matches[i] = append(j.matches[i], append([]byte{}, j.groups[i].Value()...))
+			}
+
+			j.advance(i)
+		}
+
+		if !include || j.Err() != nil {
+			continue
+		}
+
+		for i := range j.pos {
+			j.pos[i] = 0
+			if !j.found[i] {
+				j.values[i] = nil
+			} else {
+				j.values[i] = j.matches[i][0]
+			}
+		}
+
+		return true
+	}
+
+	j.done = true
+	return false
+}
+
+// advance moves the ith input on to its next group.
+func (j *Join) advance(i int) {
+	j.has[i] = j.groups[i].NextKey()
+	if j.has[i] {
+		j.keys[i] = append(j.keys[i][:0], j.groups[i].Key()...)
+	}
+}
+
+// nextCombination moves to the next combination of values for the current
+// key, like an odometer, returning false if there are no more.
+func (j *Join) nextCombination() bool {
+	for i := len(j.pos) - 1; i >= 0; i-- {
+		if !j.found[i] {
+			continue
+		}
+
+		j.pos[i]++
+		if j.pos[i] < len(j.matches[i]) {
+			j.values[i] = j.matches[i][j.pos[i]]
+			return true
+		}
+
+		j.pos[i] = 0
+		j.values[i] = j.matches[i][0]
+	}
+
+	return false
+}
+
+// Key returns the key for the current combination of records. If keys that
+// differ compare as equal, this is the key from the first input that has it.
+// The byte slice will be reused after the next call to Scan.
+func (j *Join) Key() []byte {
+	return j.key
+}
+
+// Values returns the values of the current combination of records, one for
+// each input, in order. For left and outer joins, the value for an input that
+// doesn't have the key is nil. The slice will be reused after the next call to
+// Scan, but the values within it won't change until the key does.
+func (j *Join) Values() [][]byte {
+	return j.values
+}
+
+// Err returns the first error reached while reading any of the inputs.
+func (j *Join) Err() error {
+	for _, g := range j.groups {
+		if err := g.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package sequencefile
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectJoin returns every combination from the join as a string like
+// "key:0,1", with "-" for a missing value.
+func collectJoin(t *testing.T, j *Join) []string {
+	var res []string
+	for j.Scan() {
+		values := make([]string, len(j.Values()))
+		for i, v := range j.Values() {
+			if v == nil {
+				values[i] = "-"
+			} else {
+				values[i] = fmt.Sprint(IntWritable(v))
+			}
+		}
+
+		res = append(res, Text(j.Key())+":"+strings.Join(values, ","))
+	}
+
+	require.NoError(t, j.Err())
+	assert.False(t, j.Scan())
+	return res
+}
+
+func joinTestInputs(t *testing.T) []*Reader {
+	return []*Reader{
+		writeGroups(t, []string{"a", "b", "b", "d", "e"}),
+		writeGroups(t, []string{"b", "b", "c", "d"}),
+	}
+}
+
+func TestInnerJoin(t *testing.T) {
+	j, err := NewJoin(InnerJoin, joinTestInputs(t)...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"b:1,0", "b:1,1", "b:2,0", "b:2,1",
+		"d:3,3",
+	}, collectJoin(t, j))
+}
+
+func TestLeftJoin(t *testing.T) {
+	j, err := NewJoin(LeftJoin, joinTestInputs(t)...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"a:0,-",
+		"b:1,0", "b:1,1", "b:2,0", "b:2,1",
+		"d:3,3",
+		"e:4,-",
+	}, collectJoin(t, j))
+}
+
+func TestOuterJoin(t *testing.T) {
+	j, err := NewJoin(OuterJoin, joinTestInputs(t)...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"a:0,-",
+		"b:1,0", "b:1,1", "b:2,0", "b:2,1",
+		"c:-,2",
+		"d:3,3",
+		"e:4,-",
+	}, collectJoin(t, j))
+}
+
+func TestJoinThreeInputs(t *testing.T) {
+	readers := []*Reader{
+		writeGroups(t, []string{"a", "a", "b", "c"}),
+		writeGroups(t, []string{"a", "c", "c"}),
+		writeGroups(t, []string{"a", "a", "b"}),
+	}
+
+	j, err := NewJoin(OuterJoin, readers...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"a:0,0,0", "a:0,0,1", "a:1,0,0", "a:1,0,1",
+		"b:2,-,2",
+		"c:3,1,-", "c:3,2,-",
+	}, collectJoin(t, j))
+}
+
+func TestJoinEmptyInput(t *testing.T) {
+	readers := []*Reader{
+		writeGroups(t, []string{"a", "b"}),
+		writeGroups(t, nil),
+	}
+
+	j, err := NewJoin(InnerJoin, readers...)
+	require.NoError(t, err)
+	assert.Empty(t, collectJoin(t, j))
+}
+
+func TestJoinLongKeys(t *testing.T) {
+	// LongWritable keys don't sort the same way as their bytes.
+	readers := make([]*Reader, 2)
+	for i, keys := range [][]int64{{-2, -1, 5}, {-1, 3, 5}} {
+		readers[i] = NewReader(writeLongs(t, keys, "v"))
+		require.NoError(t, readers[i].ReadHeader())
+	}
+
+	j, err := NewJoin(InnerJoin, readers...)
+	require.NoError(t, err)
+
+	var keys []int64
+	for j.Scan() {
+		keys = append(keys, LongWritable(j.Key()))
+	}
+
+	require.NoError(t, j.Err())
+	assert.Equal(t, []int64{-1, 5}, keys)
+}
+
+func TestJoinMismatchedKeys(t *testing.T) {
+	longs := NewReader(writeLongs(t, []int64{1}, "v"))
+	require.NoError(t, longs.ReadHeader())
+	readers := []*Reader{writeGroups(t, []string{"a"}), longs}
+
+	_, err := NewJoin(InnerJoin, readers...)
+	assert.Error(t, err)
+
+	_, err = NewJoin(InnerJoin, readers[0])
+	assert.Error(t, err)
+}
+
+func TestJoinUnsorted(t *testing.T) {
+	j, err := NewJoin(OuterJoin,
+		writeGroups(t, []string{"a", "b", "c"}),
+		writeGroups(t, []string{"a", "c", "b", "d"}),
+	)
+	require.NoError(t, err)
+
+	var keys []string
+	for j.Scan() {
+		keys = append(keys, Text(j.Key()))
+	}
+
+	require.Error(t, j.Err())
+	assert.Contains(t, j.Err().Error(), "input 1 is not sorted")
+	assert.Equal(t, []string{"a", "b"}, keys, "the join should stop at the first out-of-order key")
+}
@@ -0,0 +1,139 @@
+package mapfile
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+// An ArrayWriter writes an ArrayFile, which is a MapFile whose keys are the
+// record numbers, as LongWritables, like Hadoop's ArrayFile.Writer.
+type ArrayWriter struct {
+	w *Writer
+	n int64
+}
+
+// CreateArray creates a new ArrayFile in the given directory, creating the
+// directory if necessary. The KeyClass and Compare fields of cfg are ignored.
+func CreateArray(dir string, cfg *WriterConfig) (*ArrayWriter, error) {
+	c := *cfg
+	c.KeyClass = sequencefile.LongWritableClassName
+	c.Compare = nil
+	w, err := Create(dir, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArrayWriter{w: w}, nil
+}
+
+// Append adds a value to the end of the array. Its type must match the
+// ValueClass of the WriterConfig.
+func (a *ArrayWriter) Append(value interface{}) error {
+	err := a.w.Append(a.n, value)
+	if err != nil {
+		return err
+	}
+
+	a.n++
+	return nil
+}
+
+// AppendRaw adds a serialized value to the end of the array.
+func (a *ArrayWriter) AppendRaw(value []byte) error {
+	err := a.w.AppendRaw(longWritable(a.n), value)
+	if err != nil {
+		return err
+	}
+
+	a.n++
+	return nil
+}
+
+// Len returns the number of values appended so far.
+func (a *ArrayWriter) Len() int64 {
+	return a.n
+}
+
+// Close finishes writing the ArrayFile.
+func (a *ArrayWriter) Close() error {
+	return a.w.Close()
+}
+
+// Abort stops writing the ArrayFile and removes the files written so far, like
+// Writer.Abort.
+func (a *ArrayWriter) Abort() {
+	a.w.Abort()
+}
+
+// An ArrayReader reads an ArrayFile, like Hadoop's ArrayFile.Reader.
+type ArrayReader struct {
+	r *Reader
+}
+
+// OpenArray opens the ArrayFile in the given directory, and loads its index.
+func OpenArray(dir string) (*ArrayReader, error) {
+	r, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Header.KeyClassName != sequencefile.LongWritableClassName {
+		r.Close()
+		return nil, fmt.Errorf("mapfile: not an ArrayFile: the key class is %s", r.Header.KeyClassName)
+	}
+
+	return &ArrayReader{r}, nil
+}
+
+// Header returns the header of the data file.
+func (a *ArrayReader) Header() sequencefile.Header {
+	return a.r.Header
+}
+
+// Get returns the nth value in the array, or ErrNotFound if there are n or
+// fewer values. The byte slice is reused by later calls to the ArrayReader.
+func (a *ArrayReader) Get(n int64) ([]byte, error) {
+	return a.r.Get(longWritable(n))
+}
+
+// SeekRecord positions the ArrayReader so that the next call to Scan returns
+// the nth value.
+func (a *ArrayReader) SeekRecord(n int64) error {
+	return a.r.Range(longWritable(n), nil)
+}
+
+// Scan advances to the next value in the array. Unless SeekRecord is called first,
+// Scan iterates over every value.
+func (a *ArrayReader) Scan() bool {
+	return a.r.Scan()
+}
+
+// Index returns the position in the array of the current value.
+func (a *ArrayReader) Index() int64 {
+	return sequencefile.LongWritable(a.r.Key())
+}
+
+// Value returns the current value. The byte slice will be reused after the
+// next call to Scan.
+func (a *ArrayReader) Value() []byte {
+	return a.r.Value()
+}
+
+// Err returns the first error reached while scanning.
+func (a *ArrayReader) Err() error {
+	return a.r.Err()
+}
+
+// Close closes the data file.
+func (a *ArrayReader) Close() error {
+	return a.r.Close()
+}
+
+// longWritable serializes n as a LongWritable.
+func longWritable(n int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(n))
+	return b
+}
@@ -0,0 +1,56 @@
+package mapfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayFile(t *testing.T) {
+	dir := t.TempDir()
+	w, err := CreateArray(dir, &WriterConfig{
+		WriterConfig: sequencefile.WriterConfig{
+			ValueClass:       sequencefile.TextClassName,
+			Compression:      sequencefile.BlockCompression,
+			CompressionCodec: sequencefile.GzipCompression,
+			BlockSize:        500,
+		},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.Append(fmt.Sprintf("value %d", i)))
+	}
+	assert.Equal(t, int64(1000), w.Len())
+	require.NoError(t, w.Close())
+
+	a, err := OpenArray(dir)
+	require.NoError(t, err)
+	defer a.Close()
+	assert.Equal(t, sequencefile.LongWritableClassName, a.Header().KeyClassName)
+
+	for _, n := range []int64{999, 0, 500, 128, 127, 1} {
+		v, err := a.Get(n)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("value %d", n), sequencefile.Text(v))
+	}
+
+	_, err = a.Get(1000)
+	assert.Equal(t, ErrNotFound, err)
+
+	require.NoError(t, a.SeekRecord(997))
+	var indexes []int64
+	for a.Scan() {
+		indexes = append(indexes, a.Index())
+	}
+	require.NoError(t, a.Err())
+	assert.Equal(t, []int64{997, 998, 999}, indexes)
+}
+
+func TestOpenArrayNotAnArray(t *testing.T) {
+	_, err := OpenArray(writeTestMapFile(t, 10, 1, sequencefile.NoCompression))
+	assert.Error(t, err)
+}
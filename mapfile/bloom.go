@@ -0,0 +1,206 @@
+package mapfile
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// BloomFileName is the name of the file holding the bloom filter in a
+// BloomMapFile.
+const BloomFileName = "bloom"
+
+// bloomHashCount is the number of hash functions used, which Hadoop fixes at
+// five.
+const bloomHashCount = 5
+
+const (
+	defaultBloomSize      = 1024 * 1024
+	defaultBloomErrorRate = 0.005
+)
+
+// HashType specifies the hash function used by a bloom filter.
+type HashType int
+
+const (
+	// MurmurHash is Hadoop's variant of MurmurHash2, which is the default for
+	// hadoop.util.hash.type.
+	MurmurHash HashType = iota + 1
+
+	// JenkinsHash is Bob Jenkins' lookup3 hash.
+	JenkinsHash
+)
+
+// A BloomWriterConfig specifies the configuration for a BloomWriter.
+type BloomWriterConfig struct {
+	WriterConfig
+
+	// BloomSize is the number of keys that each row of the filter is sized
+	// for, like io.mapfile.bloom.size. Once that many keys are added, the
+	// filter grows by another row, and checking it takes longer. It defaults to
+	// 1048576.
+	BloomSize int
+
+	// BloomErrorRate is the target false positive rate for each row of the
+	// filter, like io.mapfile.bloom.error.rate. It defaults to 0.005.
+	BloomErrorRate float64
+
+	// Hash is the hash function to use. It defaults to MurmurHash.
+	Hash HashType
+}
+
+// A BloomWriter writes a BloomMapFile, which is a MapFile with an additional
+// file holding a bloom filter of all the keys, like Hadoop's
+// BloomMapFile.Writer. The filter is serialized in the same format as Hadoop's
+// DynamicBloomFilter.
+type BloomWriter struct {
+	*Writer
+	filter *dynamicBloomFilter
+}
+
+// CreateBloom creates a new BloomMapFile in the given directory, creating the
+// directory if necessary.
+func CreateBloom(dir string, cfg *BloomWriterConfig) (*BloomWriter, error) {
+	numKeys := cfg.BloomSize
+	if numKeys <= 0 {
+		numKeys = defaultBloomSize
+	}
+
+	errorRate := cfg.BloomErrorRate
+	if errorRate <= 0 {
+		errorRate = defaultBloomErrorRate
+	}
+
+	var hashType byte
+	switch cfg.Hash {
+	case 0, MurmurHash:
+		hashType = murmurHashType
+	case JenkinsHash:
+		hashType = jenkinsHashType
+	default:
+		return nil, errors.New("mapfile: unknown hash type")
+	}
+
+	// This is the same calculation Hadoop does.
+	vectorSize := math.Ceil(float64(-bloomHashCount*numKeys) /
+		math.Log(1.0-math.Pow(errorRate, 1.0/bloomHashCount)))
+	if vectorSize > math.MaxInt32 {
+		return nil, errors.New("mapfile: bloom filter too large")
+	}
+
+	filter, err := newDynamicBloomFilter(int32(vectorSize), bloomHashCount, hashType, int32(numKeys))
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := Create(dir, &cfg.WriterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BloomWriter{Writer: w, filter: filter}, nil
+}
+
+// Append adds a key/value pair to the BloomMapFile, like Writer.Append.
+func (w *BloomWriter) Append(key interface{}, value interface{}) error {
+	k, v, err := w.serialize(key, value)
+	if err != nil {
+		return err
+	}
+
+	return w.AppendRaw(k, v)
+}
+
+// AppendRaw adds a serialized key/value pair to the BloomMapFile, like
+// Writer.AppendRaw. Keys must not be empty, which means NullWritable keys
+// aren't supported.
+func (w *BloomWriter) AppendRaw(key, value []byte) error {
+	if len(key) == 0 {
+		return errors.New("mapfile: bloom filter keys must not be empty")
+	}
+
+	if err := w.Writer.AppendRaw(key, value); err != nil {
+		return err
+	}
+
+	w.filter.add(key)
+	return nil
+}
+
+// Close finishes writing the data and index files, writes the bloom filter,
+// and then moves all three into place. If that fails, the files are removed.
+func (w *BloomWriter) Close() error {
+	if err := w.finish(); err != nil {
+		return err
+	}
+
+	f, err := w.create(BloomFileName)
+	if err != nil {
+		w.Abort()
+		return err
+	}
+
+	if err := w.filter.write(f); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return w.commit()
+}
+
+// A BloomReader reads a BloomMapFile, like Hadoop's BloomMapFile.Reader. It
+// works just like a Reader, except that Get checks the bloom filter first,
+// which avoids reading from the data file for most keys that aren't present.
+type BloomReader struct {
+	*Reader
+	filter *dynamicBloomFilter
+}
+
+// OpenBloom opens the BloomMapFile in the given directory, and loads its index
+// and bloom filter. Like Hadoop, if there is no bloom file, OpenBloom falls
+// back to reading the directory as a plain MapFile.
+func OpenBloom(dir string) (*BloomReader, error) {
+	r, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, BloomFileName))
+	if os.IsNotExist(err) {
+		return &BloomReader{Reader: r}, nil
+	} else if err != nil {
+		r.Close()
+		return nil, err
+	}
+	defer f.Close()
+
+	filter, err := readDynamicBloomFilter(bufio.NewReader(f))
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return &BloomReader{Reader: r, filter: filter}, nil
+}
+
+// ProbablyHasKey returns false if the serialized key is definitely not in the
+// BloomMapFile, or true if it might be.
+func (r *BloomReader) ProbablyHasKey(key []byte) bool {
+	if r.filter == nil || len(key) == 0 {
+		return true
+	}
+
+	return r.filter.membershipTest(key)
+}
+
+// Get returns the value for the given key, or ErrNotFound if it isn't in the
+// BloomMapFile, like Reader.Get.
+func (r *BloomReader) Get(key []byte) ([]byte, error) {
+	if !r.ProbablyHasKey(key) {
+		return nil, ErrNotFound
+	}
+
+	return r.Reader.Get(key)
+}
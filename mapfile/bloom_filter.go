@@ -0,0 +1,225 @@
+package mapfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// filterVersion is the version of Hadoop's Filter serialization.
+const filterVersion = -1
+
+// The hash types understood by Hadoop, as serialized.
+const (
+	jenkinsHashType = 0
+	murmurHashType  = 1
+)
+
+// A dynamicBloomFilter is a compatible implementation of Hadoop's
+// DynamicBloomFilter, which is a list of bloom filters, or rows, of the same
+// size. Keys are added to the last row until it holds nr keys, and then a new
+// row is started, so that the false positive rate stays bounded no matter how
+// many keys are added.
+type dynamicBloomFilter struct {
+	vectorSize int32
+	nbHash     int32
+	hashType   byte
+	hash       func([]byte, int32) int32
+
+	nr              int32
+	currentNbRecord int32
+
+	// Each row is a bit vector, with bit i stored in rows[n][i/8] as 1<<(i%8),
+	// the same as in the serialized form.
+	rows [][]byte
+}
+
+func newDynamicBloomFilter(vectorSize, nbHash int32, hashType byte, nr int32) (*dynamicBloomFilter, error) {
+	f := &dynamicBloomFilter{
+		vectorSize: vectorSize,
+		nbHash:     nbHash,
+		hashType:   hashType,
+		nr:         nr,
+	}
+
+	if err := f.init(); err != nil {
+		return nil, err
+	}
+
+	f.rows = [][]byte{f.newRow()}
+	return f, nil
+}
+
+func (f *dynamicBloomFilter) init() error {
+	switch f.hashType {
+	case jenkinsHashType:
+		f.hash = jenkinsHash
+	case murmurHashType:
+		f.hash = murmurHash
+	default:
+		return fmt.Errorf("mapfile: unknown bloom filter hash type: %d", f.hashType)
+	}
+
+	if f.vectorSize <= 0 || f.nbHash <= 0 || f.nr <= 0 {
+		return errors.New("mapfile: invalid bloom filter")
+	}
+
+	return nil
+}
+
+func (f *dynamicBloomFilter) newRow() []byte {
+	return make([]byte, (f.vectorSize+7)/8)
+}
+
+// positions returns the bits for a key, like Hadoop's HashFunction.
+func (f *dynamicBloomFilter) positions(key []byte) []int32 {
+	res := make([]int32, f.nbHash)
+	initval := int32(0)
+	for i := range res {
+		initval = f.hash(key, initval)
+		p := initval % f.vectorSize
+		if p < 0 {
+			p = -p
+		}
+
+		res[i] = p
+	}
+
+	return res
+}
+
+func (f *dynamicBloomFilter) add(key []byte) {
+	if f.currentNbRecord >= f.nr {
+		f.rows = append(f.rows, f.newRow())
+		f.currentNbRecord = 0
+	}
+
+	row := f.rows[len(f.rows)-1]
+	for _, p := range f.positions(key) {
+		row[p/8] |= 1 << (p % 8)
+	}
+
+	f.currentNbRecord++
+}
+
+func (f *dynamicBloomFilter) membershipTest(key []byte) bool {
+	positions := f.positions(key)
+	for _, row := range f.rows {
+		found := true
+		for _, p := range positions {
+			if row[p/8]&(1<<(p%8)) == 0 {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *dynamicBloomFilter) writeHeader(w io.Writer) error {
+	return binary.Write(w, binary.BigEndian, struct {
+		Version    int32
+		NbHash     int32
+		HashType   byte
+		VectorSize int32
+	}{filterVersion, f.nbHash, f.hashType, f.vectorSize})
+}
+
+// write serializes the filter in the same format as DynamicBloomFilter.write.
+func (f *dynamicBloomFilter) write(w io.Writer) error {
+	if err := f.writeHeader(w); err != nil {
+		return err
+	}
+
+	err := binary.Write(w, binary.BigEndian, []int32{f.nr, f.currentNbRecord, int32(len(f.rows))})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range f.rows {
+		if err := f.writeHeader(w); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFilterHeader reads the fields written by Hadoop's Filter.write, which
+// both the DynamicBloomFilter and each of its rows start with.
+func readFilterHeader(r io.Reader) (nbHash int32, hashType byte, vectorSize int32, err error) {
+	var version int32
+	if err = binary.Read(r, binary.BigEndian, &version); err != nil {
+		return
+	}
+
+	if version > 0 {
+		// The old, unversioned format.
+		nbHash = version
+		hashType = jenkinsHashType
+	} else if version == filterVersion {
+		if err = binary.Read(r, binary.BigEndian, &nbHash); err != nil {
+			return
+		}
+		if err = binary.Read(r, binary.BigEndian, &hashType); err != nil {
+			return
+		}
+	} else {
+		err = fmt.Errorf("mapfile: unsupported bloom filter version: %d", version)
+		return
+	}
+
+	err = binary.Read(r, binary.BigEndian, &vectorSize)
+	return
+}
+
+// readDynamicBloomFilter deserializes a filter written by
+// DynamicBloomFilter.write.
+func readDynamicBloomFilter(r io.Reader) (*dynamicBloomFilter, error) {
+	f := &dynamicBloomFilter{}
+	var err error
+	f.nbHash, f.hashType, f.vectorSize, err = readFilterHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts [3]int32
+	if err := binary.Read(r, binary.BigEndian, &counts); err != nil {
+		return nil, err
+	}
+
+	f.nr, f.currentNbRecord = counts[0], counts[1]
+	if err := f.init(); err != nil {
+		return nil, err
+	}
+
+	// The number of rows isn't used to preallocate anything, so that a corrupt
+	// file results in an error rather than a huge allocation.
+	for i := int32(0); i < counts[2]; i++ {
+		nbHash, hashType, vectorSize, err := readFilterHeader(r)
+		if err != nil {
+			return nil, err
+		} else if nbHash != f.nbHash || hashType != f.hashType || vectorSize != f.vectorSize {
+			return nil, errors.New("mapfile: bloom filter rows don't match")
+		}
+
+		row := f.newRow()
+		if _, err := io.ReadFull(r, row); err != nil {
+			return nil, err
+		}
+
+		f.rows = append(f.rows, row)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,119 @@
+package mapfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomMapFile(t *testing.T) {
+	for _, hash := range []HashType{MurmurHash, JenkinsHash} {
+		for _, bloomSize := range []int{0, 100} {
+			t.Run(fmt.Sprintf("%d/%d", hash, bloomSize), func(t *testing.T) {
+				r, err := OpenBloom(writeTestBloomMapFile(t, hash, bloomSize))
+				require.NoError(t, err)
+				defer r.Close()
+
+				if bloomSize == 100 {
+					assert.Len(t, r.filter.rows, 5, "the filter should grow by a row every 100 keys")
+				}
+
+				for i := 0; i < 1000; i += 2 {
+					key := text(fmt.Sprintf("key-%04d", i))
+					require.True(t, r.ProbablyHasKey(key), "there should be no false negatives")
+
+					v, err := r.Get(key)
+					require.NoError(t, err)
+					assert.Equal(t, int32(i), sequencefile.IntWritable(v))
+				}
+
+				falsePositives := 0
+				for i := 1; i < 1000; i += 2 {
+					key := text(fmt.Sprintf("key-%04d", i))
+					if r.ProbablyHasKey(key) {
+						falsePositives++
+					}
+
+					_, err := r.Get(key)
+					assert.Equal(t, ErrNotFound, err)
+				}
+
+				assert.Less(t, falsePositives, 25)
+			})
+		}
+	}
+}
+
+func TestBloomFilterFormat(t *testing.T) {
+	f, err := newDynamicBloomFilter(100, 5, murmurHashType, 10)
+	require.NoError(t, err)
+
+	// The key "hello" as Text, which sets bits 4, 11, 77, 81 and 91.
+	key := []byte("\x05hello")
+	f.add(key)
+
+	var buf bytes.Buffer
+	require.NoError(t, f.write(&buf))
+
+	header := []byte{
+		0xff, 0xff, 0xff, 0xff, // version
+		0x00, 0x00, 0x00, 0x05, // nbHash
+		0x01,                   // hashType
+		0x00, 0x00, 0x00, 0x64, // vectorSize
+	}
+
+	expected := append([]byte{}, header...)
+	expected = append(expected,
+		0x00, 0x00, 0x00, 0x0a, // nr
+		0x00, 0x00, 0x00, 0x01, // currentNbRecord
+		0x00, 0x00, 0x00, 0x01, // rows
+	)
+
+	row := make([]byte, 13)
+	for _, bit := range []int{4, 11, 77, 81, 91} {
+		row[bit/8] |= 1 << (bit % 8)
+	}
+
+	expected = append(expected, header...)
+	expected = append(expected, row...)
+	assert.Equal(t, expected, buf.Bytes())
+
+	read, err := readDynamicBloomFilter(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, f.rows, read.rows)
+	assert.True(t, read.membershipTest(key))
+	assert.False(t, read.membershipTest([]byte("\x05world")))
+}
+
+func TestReadBloomFilterUnversioned(t *testing.T) {
+	// Before versioning, the header was just the number of hashes and the
+	// vector size, and the hash was always Jenkins.
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []int32{3, 16, 4, 0, 1, 3, 16})
+	buf.Write([]byte{0xff, 0xff})
+
+	f, err := readDynamicBloomFilter(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, byte(jenkinsHashType), f.hashType)
+	assert.True(t, f.membershipTest([]byte("anything")))
+}
+
+func TestOpenBloomWithoutFilter(t *testing.T) {
+	dir := writeTestBloomMapFile(t, MurmurHash, 0)
+	require.NoError(t, os.Remove(filepath.Join(dir, BloomFileName)))
+
+	r, err := OpenBloom(dir)
+	require.NoError(t, err)
+	defer r.Close()
+
+	v, err := r.Get(text("key-0010"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), sequencefile.IntWritable(v))
+}
@@ -0,0 +1,100 @@
+package mapfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/require"
+)
+
+// text serializes a Text.
+func text(s string) []byte {
+	write, _ := sequencefile.NewWritableWriter(sequencefile.TextClassName)
+
+	var buf bytes.Buffer
+	write(&buf, s)
+	return buf.Bytes()
+}
+
+// appendTestRecords appends records with Text keys "key-0000", "key-0002" and
+// so on (only even numbers) up to n, and IntWritable values.
+func appendTestRecords(t *testing.T, appendFn func(key, value interface{}) error, n int) {
+	for i := 0; i < n; i += 2 {
+		require.NoError(t, appendFn(fmt.Sprintf("key-%04d", i), int32(i)))
+	}
+}
+
+// writeTestMapFile writes a MapFile with Text keys "key-0000", "key-0002" and
+// so on (only even numbers) up to n, and IntWritable values. The index is
+// built by reading the data file back, in the same way as Hadoop's
+// MapFile.Writer, with an entry for every interval keys.
+func writeTestMapFile(t *testing.T, n, interval int, compression sequencefile.Compression) string {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, DataFileName))
+	require.NoError(t, err)
+
+	cfg := &sequencefile.WriterConfig{
+		Writer:     f,
+		KeyClass:   sequencefile.TextClassName,
+		ValueClass: sequencefile.IntWritableClassName,
+		BlockSize:  100,
+	}
+	if compression != sequencefile.NoCompression {
+		cfg.Compression = compression
+		cfg.CompressionCodec = sequencefile.GzipCompression
+	}
+
+	w, err := sequencefile.NewWriter(cfg)
+	require.NoError(t, err)
+	appendTestRecords(t, w.Append, n)
+	require.NoError(t, w.Close())
+
+	data, err := sequencefile.Open(filepath.Join(dir, DataFileName))
+	require.NoError(t, err)
+	defer data.Close()
+
+	f, err = os.Create(filepath.Join(dir, IndexFileName))
+	require.NoError(t, err)
+	index, err := sequencefile.NewWriter(&sequencefile.WriterConfig{
+		Writer:     f,
+		KeyClass:   sequencefile.TextClassName,
+		ValueClass: sequencefile.LongWritableClassName,
+	})
+	require.NoError(t, err)
+
+	for i := 0; data.Scan(); i++ {
+		if i%interval == 0 {
+			require.NoError(t, index.AppendRaw(data.Key(), longWritable(data.Offset())))
+		}
+	}
+	require.NoError(t, data.Err())
+	require.NoError(t, index.Close())
+
+	return dir
+}
+
+// writeTestBloomMapFile writes a BloomMapFile with the same records as
+// writeTestMapFile, up to 1000, using BloomWriter.
+func writeTestBloomMapFile(t *testing.T, hash HashType, bloomSize int) string {
+	dir := t.TempDir()
+	w, err := CreateBloom(dir, &BloomWriterConfig{
+		WriterConfig: WriterConfig{
+			WriterConfig: sequencefile.WriterConfig{
+				KeyClass:   sequencefile.TextClassName,
+				ValueClass: sequencefile.IntWritableClassName,
+			},
+		},
+		BloomSize: bloomSize,
+		Hash:      hash,
+	})
+	require.NoError(t, err)
+
+	appendTestRecords(t, w.Append, 1000)
+	require.NoError(t, w.Close())
+
+	return dir
+}
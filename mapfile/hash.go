@@ -0,0 +1,103 @@
+package mapfile
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// jenkinsHash implements Hadoop's JenkinsHash, which is Bob Jenkins' lookup3
+// hashlittle function.
+func jenkinsHash(key []byte, initval int32) int32 {
+	length := len(key)
+	a := 0xdeadbeef + uint32(length) + uint32(initval)
+	b, c := a, a
+
+	for ; length > 12; length -= 12 {
+		a += binary.LittleEndian.Uint32(key[0:])
+		b += binary.LittleEndian.Uint32(key[4:])
+		c += binary.LittleEndian.Uint32(key[8:])
+
+		a -= c
+		a ^= bits.RotateLeft32(c, 4)
+		c += b
+		b -= a
+		b ^= bits.RotateLeft32(a, 6)
+		a += c
+		c -= b
+		c ^= bits.RotateLeft32(b, 8)
+		b += a
+		a -= c
+		a ^= bits.RotateLeft32(c, 16)
+		c += b
+		b -= a
+		b ^= bits.RotateLeft32(a, 19)
+		a += c
+		c -= b
+		c ^= bits.RotateLeft32(b, 4)
+		b += a
+
+		key = key[12:]
+	}
+
+	if length == 0 {
+		return int32(c)
+	}
+
+	// The last block: up to 12 bytes, added little-endian to a, b and c.
+	var tail [12]byte
+	copy(tail[:], key)
+	a += binary.LittleEndian.Uint32(tail[0:])
+	b += binary.LittleEndian.Uint32(tail[4:])
+	c += binary.LittleEndian.Uint32(tail[8:])
+
+	c ^= b
+	c -= bits.RotateLeft32(b, 14)
+	a ^= c
+	a -= bits.RotateLeft32(c, 11)
+	b ^= a
+	b -= bits.RotateLeft32(a, 25)
+	c ^= b
+	c -= bits.RotateLeft32(b, 16)
+	a ^= c
+	a -= bits.RotateLeft32(c, 4)
+	b ^= a
+	b -= bits.RotateLeft32(a, 14)
+	c ^= b
+	c -= bits.RotateLeft32(b, 24)
+	return int32(c)
+}
+
+// murmurHash implements Hadoop's MurmurHash, which is MurmurHash2, except
+// that the trailing bytes are sign-extended.
+func murmurHash(data []byte, seed int32) int32 {
+	const m = 0x5bd1e995
+	const r = 24
+
+	length := len(data)
+	h := uint32(seed) ^ uint32(length)
+	for ; len(data) >= 4; data = data[4:] {
+		k := binary.LittleEndian.Uint32(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	switch len(data) {
+	case 3:
+		h ^= uint32(int32(int8(data[2])) << 16)
+		fallthrough
+	case 2:
+		h ^= uint32(int32(int8(data[1])) << 8)
+		fallthrough
+	case 1:
+		h ^= uint32(int32(int8(data[0])))
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
@@ -0,0 +1,48 @@
+package mapfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The jenkinsHash values for "Four score and seven years ago" are the test
+// vectors from lookup3.c (0x17770551 and 0xcd628161). The rest are from a
+// separate implementation of Hadoop's versions.
+var hashes = []struct {
+	s          string
+	jenkins    int32
+	jenkins1   int32
+	murmur     int32
+	murmurNeg7 int32
+}{
+	{"", -559038737, -559038736, 0, -633156104},
+	{"a", 1490454280, 1118926449, -1838653602, 515141137},
+	{"ab", -72111905, -89110158, 446775395, -1203048794},
+	{"abc", 238646833, -101675362, 324500635, -490428305},
+	{"abcd", -1242265444, -1771439836, 646393889, 619518759},
+	{"hello world", 1252609637, 345455448, 1151865881, 1613255212},
+	{"Four score and seven years ago", 0x17770551, -849182367, -2065581118, -1013169554},
+	{"\xff\xfe\x80", -352376565, 146189058, 385410881, 1082207969},
+	{"0123456789ab", 275113226, -2088255349, 123333918, 305630431},
+	{"0123456789abc", 1934741078, -1763215915, 1712711356, 824941765},
+}
+
+func TestJenkinsHash(t *testing.T) {
+	for _, spec := range hashes {
+		t.Run(fmt.Sprintf("%q", spec.s), func(t *testing.T) {
+			assert.Equal(t, spec.jenkins, jenkinsHash([]byte(spec.s), 0))
+			assert.Equal(t, spec.jenkins1, jenkinsHash([]byte(spec.s), 1))
+		})
+	}
+}
+
+func TestMurmurHash(t *testing.T) {
+	for _, spec := range hashes {
+		t.Run(fmt.Sprintf("%q", spec.s), func(t *testing.T) {
+			assert.Equal(t, spec.murmur, murmurHash([]byte(spec.s), 0))
+			assert.Equal(t, spec.murmurNeg7, murmurHash([]byte(spec.s), -7))
+		})
+	}
+}
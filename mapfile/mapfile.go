@@ -0,0 +1,30 @@
+// Package mapfile implements Hadoop's MapFile format, which is a directory
+// holding a sorted SequenceFile along with an index that allows looking up
+// records by key.
+//
+// The directory contains two SequenceFiles. The first, called data, holds the
+// records, in order by key. The second, called index, maps every Nth key in
+// data to the offset of that record (or of the block containing it, for
+// block-compressed files), as a LongWritable. The index is small enough to be
+// loaded into memory, and looking up a key means finding the closest entry in
+// the index that comes before it, seeking to that offset in data, and
+// scanning forward.
+//
+// The package also supports Hadoop's SetFile, which is a MapFile with
+// NullWritable values, and ArrayFile, which is a MapFile whose keys are the
+// record numbers, and BloomMapFile, which adds a bloom filter of the keys to
+// speed up lookups of keys that aren't present.
+package mapfile
+
+import "errors"
+
+const (
+	// DataFileName is the name of the file holding the records.
+	DataFileName = "data"
+
+	// IndexFileName is the name of the file holding the index.
+	IndexFileName = "index"
+)
+
+// ErrNotFound is returned when looking up a key that isn't in the MapFile.
+var ErrNotFound = errors.New("mapfile: key not found")
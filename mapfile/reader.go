@@ -0,0 +1,265 @@
+package mapfile
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+// A Reader looks up records in a MapFile by key, and iterates over ranges of
+// keys in order.
+//
+// Keys are passed to and returned from the Reader serialized, in the same
+// form as sequencefile.Reader.Key returns them.
+type Reader struct {
+	// Header is the header of the data file.
+	Header sequencefile.Header
+
+	data  *sequencefile.Reader
+	cmp   sequencefile.RawComparator
+	first int64
+
+	// The index, with the positions of each key in data.
+	keys      [][]byte
+	positions []int64
+
+	// Iteration state. If pending is set, the data Reader is already at the
+	// next record to return.
+	pending bool
+	end     []byte
+	done    bool
+	err     error
+}
+
+// Open opens the MapFile in the given directory, and loads its index. Keys are
+// ordered with the RawComparator for the key class of the data file.
+func Open(dir string) (*Reader, error) {
+	return OpenComparator(dir, nil)
+}
+
+// OpenComparator opens the MapFile in the given directory, like Open, but
+// orders keys with the given comparator, which must match the one the
+// MapFile was written with. If cmp is nil, the RawComparator for the key class
+// of the data file is used.
+func OpenComparator(dir string, cmp sequencefile.RawComparator) (*Reader, error) {
+	data, err := sequencefile.Open(filepath.Join(dir, DataFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{Header: data.Header, data: data, cmp: cmp, first: data.Offset()}
+	if r.cmp == nil {
+		r.cmp, err = sequencefile.NewRawComparator(data.Header.KeyClassName)
+		if err != nil {
+			data.Close()
+			return nil, err
+		}
+	}
+
+	err = r.readIndex(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Reader) readIndex(path string) error {
+	index, err := sequencefile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+
+	if index.Header.KeyClassName != r.Header.KeyClassName {
+		return fmt.Errorf("mapfile: index key class doesn't match data: %s != %s",
+			index.Header.KeyClassName, r.Header.KeyClassName)
+	} else if index.Header.ValueClassName != sequencefile.LongWritableClassName {
+		return fmt.Errorf("mapfile: invalid index value class: %s", index.Header.ValueClassName)
+	}
+
+	for index.Scan() {
+		r.keys = append(r.keys, append([]byte(nil), index.Key()...))
+		r.positions = append(r.positions, sequencefile.LongWritable(index.Value()))
+	}
+
+	if index.Err() != nil {
+		return fmt.Errorf("mapfile: reading index: %s", index.Err())
+	}
+
+	return nil
+}
+
+// Get returns the value for the given key, or ErrNotFound if it isn't in the
+// MapFile. If there is more than one record with the key, the value of the
+// first one is returned. The byte slice is reused by later calls to the
+// Reader.
+func (r *Reader) Get(key []byte) ([]byte, error) {
+	found, err := r.seek(key)
+	if err != nil {
+		return nil, err
+	} else if !found || r.cmp(r.data.Key(), key) != 0 {
+		return nil, ErrNotFound
+	}
+
+	return r.data.Value(), nil
+}
+
+// GetClosest returns the first record with a key greater than or equal to the
+// given one. If before is set, it instead returns the last record with a key
+// less than or equal to the given one. If there is no such record, it returns
+// ErrNotFound. The byte slices are reused by later calls to the Reader.
+func (r *Reader) GetClosest(key []byte, before bool) ([]byte, []byte, error) {
+	if !before {
+		found, err := r.seek(key)
+		if err != nil {
+			return nil, nil, err
+		} else if !found {
+			return nil, nil, ErrNotFound
+		}
+
+		return r.data.Key(), r.data.Value(), nil
+	}
+
+	// Find the last record before the first one greater than the key, by
+	// scanning forward from the closest index entry before it.
+	var prevKey, prevValue []byte
+	ok := false
+	err := r.seekIndex(key, true)
+	for err == nil && r.data.Scan() {
+		if r.cmp(r.data.Key(), key) > 0 {
+			break
+		}
+
+		prevKey = append(prevKey[:0], r.data.Key()...)
+		prevValue = append(prevValue[:0], r.data.Value()...)
+		ok = true
+	}
+
+	if err == nil {
+		err = r.data.Err()
+	}
+
+	// The data Reader is left somewhere arbitrary.
+	r.pending = false
+	r.done = true
+	if err != nil {
+		return nil, nil, err
+	} else if !ok {
+		return nil, nil, ErrNotFound
+	}
+
+	return prevKey, prevValue, nil
+}
+
+// Range positions the Reader to iterate over the records with keys greater
+// than or equal to start and less than end, in order, using Scan. If start is
+// nil, iteration starts at the first record, and if end is nil, it continues
+// to the last.
+func (r *Reader) Range(start, end []byte) error {
+	r.end = end
+	if start == nil {
+		r.pending = false
+		r.done = false
+		_, r.err = r.data.Seek(r.first, io.SeekStart)
+		return r.err
+	}
+
+	_, err := r.seek(start)
+	return err
+}
+
+// Scan advances to the next record in the current range, reading the key and
+// value into memory. These can then be obtained by calling Key and Value. If
+// the end of the range is reached, or there is an error, Scan will return
+// false. Unless Range is called first, Scan iterates over every record.
+func (r *Reader) Scan() bool {
+	if r.done || r.err != nil {
+		return false
+	}
+
+	if r.pending {
+		r.pending = false
+	} else if !r.data.Scan() {
+		r.done = true
+		return false
+	}
+
+	if r.end != nil && r.cmp(r.data.Key(), r.end) >= 0 {
+		r.done = true
+		return false
+	}
+
+	return true
+}
+
+// Key returns the key for the current record. The byte slice will be reused
+// after the next call to Scan.
+func (r *Reader) Key() []byte {
+	return r.data.Key()
+}
+
+// Value returns the value for the current record. The byte slice will be
+// reused after the next call to Scan.
+func (r *Reader) Value() []byte {
+	return r.data.Value()
+}
+
+// Err returns the first error reached while scanning.
+func (r *Reader) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.data.Err()
+}
+
+// Close closes the data file.
+func (r *Reader) Close() error {
+	return r.data.Close()
+}
+
+// seek positions the data Reader at the first record with a key greater than
+// or equal to the given one, such that the next call to Scan returns it. It
+// returns false if there is no such record.
+func (r *Reader) seek(key []byte) (bool, error) {
+	r.pending = false
+	r.done = false
+	r.err = r.seekIndex(key, false)
+	if r.err != nil {
+		return false, r.err
+	}
+
+	for r.data.Scan() {
+		if r.cmp(r.data.Key(), key) >= 0 {
+			r.pending = true
+			return true, nil
+		}
+	}
+
+	r.done = true
+	r.err = r.data.Err()
+	return false, r.err
+}
+
+// seekIndex seeks the data Reader to the position of the last index entry
+// with a key less than the given one, or less than or equal to it if
+// inclusive is set, or to the first record if there isn't one.
+func (r *Reader) seekIndex(key []byte, inclusive bool) error {
+	i := sort.Search(len(r.keys), func(i int) bool {
+		c := r.cmp(r.keys[i], key)
+		return c > 0 || (c == 0 && !inclusive)
+	})
+
+	pos := r.first
+	if i > 0 {
+		pos = r.positions[i-1]
+	}
+
+	_, err := r.data.Seek(pos, io.SeekStart)
+	return err
+}
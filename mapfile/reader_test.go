@@ -0,0 +1,126 @@
+package mapfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var compressions = []sequencefile.Compression{
+	sequencefile.NoCompression,
+	sequencefile.RecordCompression,
+	sequencefile.BlockCompression,
+}
+
+func TestGet(t *testing.T) {
+	for _, compression := range compressions {
+		t.Run(compression.String(), func(t *testing.T) {
+			r, err := Open(writeTestMapFile(t, 1000, 7, compression))
+			require.NoError(t, err)
+			defer r.Close()
+
+			// Look keys up out of order, to exercise seeking backwards.
+			for _, i := range []int{500, 0, 998, 2, 14, 16, 400, 12} {
+				v, err := r.Get(text(fmt.Sprintf("key-%04d", i)))
+				require.NoError(t, err, "key-%04d", i)
+				assert.Equal(t, int32(i), sequencefile.IntWritable(v))
+			}
+
+			for _, k := range []string{"key-0001", "key-0999", "key-1000", "a", "z"} {
+				_, err := r.Get(text(k))
+				assert.Equal(t, ErrNotFound, err, k)
+			}
+		})
+	}
+}
+
+func TestGetClosest(t *testing.T) {
+	for _, compression := range compressions {
+		t.Run(compression.String(), func(t *testing.T) {
+			r, err := Open(writeTestMapFile(t, 1000, 7, compression))
+			require.NoError(t, err)
+			defer r.Close()
+
+			k, v, err := r.GetClosest(text("key-0501"), false)
+			require.NoError(t, err)
+			assert.Equal(t, "key-0502", sequencefile.Text(k))
+			assert.Equal(t, int32(502), sequencefile.IntWritable(v))
+
+			k, v, err = r.GetClosest(text("key-0501"), true)
+			require.NoError(t, err)
+			assert.Equal(t, "key-0500", sequencefile.Text(k))
+			assert.Equal(t, int32(500), sequencefile.IntWritable(v))
+
+			k, _, err = r.GetClosest(text("key-0500"), true)
+			require.NoError(t, err)
+			assert.Equal(t, "key-0500", sequencefile.Text(k), "an exact match should be returned")
+
+			k, _, err = r.GetClosest(text("a"), false)
+			require.NoError(t, err)
+			assert.Equal(t, "key-0000", sequencefile.Text(k))
+
+			k, _, err = r.GetClosest(text("z"), true)
+			require.NoError(t, err)
+			assert.Equal(t, "key-0998", sequencefile.Text(k))
+
+			_, _, err = r.GetClosest(text("a"), true)
+			assert.Equal(t, ErrNotFound, err)
+			_, _, err = r.GetClosest(text("z"), false)
+			assert.Equal(t, ErrNotFound, err)
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	for _, compression := range compressions {
+		t.Run(compression.String(), func(t *testing.T) {
+			r, err := Open(writeTestMapFile(t, 1000, 7, compression))
+			require.NoError(t, err)
+			defer r.Close()
+
+			collect := func() []int32 {
+				var values []int32
+				for r.Scan() {
+					values = append(values, sequencefile.IntWritable(r.Value()))
+				}
+				require.NoError(t, r.Err())
+				return values
+			}
+
+			assert.Len(t, collect(), 500, "Scan should iterate over everything by default")
+
+			require.NoError(t, r.Range(text("key-0101"), text("key-0110")))
+			assert.Equal(t, []int32{102, 104, 106, 108}, collect())
+
+			require.NoError(t, r.Range(nil, text("key-0004")))
+			assert.Equal(t, []int32{0, 2}, collect())
+
+			require.NoError(t, r.Range(text("key-0994"), nil))
+			assert.Equal(t, []int32{994, 996, 998}, collect())
+
+			require.NoError(t, r.Range(text("z"), nil))
+			assert.Empty(t, collect())
+		})
+	}
+}
+
+func TestOpenMismatchedIndex(t *testing.T) {
+	dir := writeTestMapFile(t, 10, 1, sequencefile.NoCompression)
+	f, err := os.Create(filepath.Join(dir, IndexFileName))
+	require.NoError(t, err)
+	w, err := sequencefile.NewWriter(&sequencefile.WriterConfig{
+		Writer:     f,
+		KeyClass:   sequencefile.LongWritableClassName,
+		ValueClass: sequencefile.LongWritableClassName,
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = Open(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package mapfile
+
+import (
+	"fmt"
+
+	"github.com/colinmarc/sequencefile"
+)
+
+// A SetWriter writes a SetFile, which is a MapFile with NullWritable values,
+// like Hadoop's SetFile.Writer. Keys must be appended in sorted order.
+type SetWriter struct {
+	w *Writer
+}
+
+// CreateSet creates a new SetFile in the given directory, creating the
+// directory if necessary. The ValueClass of cfg is ignored.
+func CreateSet(dir string, cfg *WriterConfig) (*SetWriter, error) {
+	c := *cfg
+	c.ValueClass = sequencefile.NullWritableClassName
+	w, err := Create(dir, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SetWriter{w}, nil
+}
+
+// Append adds a key to the set. Its type must match the KeyClass of the
+// WriterConfig, and it must not sort before the previous one.
+func (s *SetWriter) Append(key interface{}) error {
+	return s.w.Append(key, nil)
+}
+
+// AppendRaw adds a serialized key to the set. It must not sort before the
+// previous one.
+func (s *SetWriter) AppendRaw(key []byte) error {
+	return s.w.AppendRaw(key, nil)
+}
+
+// Close finishes writing the SetFile.
+func (s *SetWriter) Close() error {
+	return s.w.Close()
+}
+
+// Abort stops writing the SetFile and removes the files written so far, like
+// Writer.Abort.
+func (s *SetWriter) Abort() {
+	s.w.Abort()
+}
+
+// A SetReader reads a SetFile, like Hadoop's SetFile.Reader.
+type SetReader struct {
+	r *Reader
+}
+
+// OpenSet opens the SetFile in the given directory, and loads its index.
+func OpenSet(dir string) (*SetReader, error) {
+	r, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Header.ValueClassName != sequencefile.NullWritableClassName {
+		r.Close()
+		return nil, fmt.Errorf("mapfile: not a SetFile: the value class is %s", r.Header.ValueClassName)
+	}
+
+	return &SetReader{r}, nil
+}
+
+// Header returns the header of the data file.
+func (s *SetReader) Header() sequencefile.Header {
+	return s.r.Header
+}
+
+// Contains returns true if the serialized key is in the set.
+func (s *SetReader) Contains(key []byte) (bool, error) {
+	_, err := s.r.Get(key)
+	if err == ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Range positions the SetReader to iterate over the keys greater than or
+// equal to start and less than end, as with Reader.Range.
+func (s *SetReader) Range(start, end []byte) error {
+	return s.r.Range(start, end)
+}
+
+// Scan advances to the next key in the current range. Unless Range is called
+// first, Scan iterates over every key.
+func (s *SetReader) Scan() bool {
+	return s.r.Scan()
+}
+
+// Key returns the current key. The byte slice will be reused after the next
+// call to Scan.
+func (s *SetReader) Key() []byte {
+	return s.r.Key()
+}
+
+// Err returns the first error reached while scanning.
+func (s *SetReader) Err() error {
+	return s.r.Err()
+}
+
+// Close closes the data file.
+func (s *SetReader) Close() error {
+	return s.r.Close()
+}
@@ -0,0 +1,48 @@
+package mapfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFile(t *testing.T) {
+	dir := t.TempDir()
+	w, err := CreateSet(dir, &WriterConfig{
+		WriterConfig:  sequencefile.WriterConfig{KeyClass: sequencefile.TextClassName},
+		IndexInterval: 10,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 300; i += 3 {
+		require.NoError(t, w.Append(fmt.Sprintf("member-%03d", i)))
+	}
+	require.NoError(t, w.Close())
+
+	s, err := OpenSet(dir)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, sequencefile.NullWritableClassName, s.Header().ValueClassName)
+
+	for _, i := range []int{0, 3, 297, 150, 151, 299, 1} {
+		ok, err := s.Contains(text(fmt.Sprintf("member-%03d", i)))
+		require.NoError(t, err)
+		assert.Equal(t, i%3 == 0, ok, "member-%03d", i)
+	}
+
+	require.NoError(t, s.Range(text("member-100"), text("member-110")))
+	var keys []string
+	for s.Scan() {
+		keys = append(keys, sequencefile.Text(s.Key()))
+	}
+	require.NoError(t, s.Err())
+	assert.Equal(t, []string{"member-102", "member-105", "member-108"}, keys)
+}
+
+func TestOpenSetNotASet(t *testing.T) {
+	_, err := OpenSet(writeTestMapFile(t, 10, 1, sequencefile.NoCompression))
+	assert.Error(t, err)
+}
@@ -0,0 +1,260 @@
+package mapfile
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/colinmarc/sequencefile/internal/pending"
+)
+
+// DefaultIndexInterval is the default number of keys between index entries,
+// which is the same as Hadoop's default for io.map.index.interval.
+const DefaultIndexInterval = 128
+
+// A WriterConfig specifies the configuration for a Writer.
+type WriterConfig struct {
+	// WriterConfig is used to create the data file. Its Writer field is
+	// ignored.
+	sequencefile.WriterConfig
+
+	// IndexInterval is the number of keys between index entries. It defaults
+	// to DefaultIndexInterval.
+	IndexInterval int
+
+	// Compare, if set, is used to check that keys are in order, instead of the
+	// RawComparator for KeyClass. Readers must use the same comparator.
+	Compare sequencefile.RawComparator
+}
+
+// A Writer writes key/value pairs to a new MapFile. Keys must be appended in
+// sorted order, though there may be more than one record with the same key.
+//
+// Like Hadoop's MapFile.Writer, the index is block-compressed with zlib, and
+// has at most one entry per block of the data file.
+//
+// The files are written under temporary names, starting with '.', and only
+// moved into place by Close, so that an unfinished MapFile is never visible.
+// If writing fails, call Abort to remove them.
+type Writer struct {
+	dir   string
+	data  *sequencefile.Writer
+	index *sequencefile.Writer
+	files []*pending.File
+
+	keyClass    string
+	valueClass  string
+	keyWriter   sequencefile.WritableWriter
+	valueWriter sequencefile.WritableWriter
+	cmp         sequencefile.RawComparator
+	interval    int
+
+	lastKey       []byte
+	size          int64
+	lastIndexPos  int64
+	lastIndexSize int64
+}
+
+// Create creates a new MapFile in the given directory, creating the directory
+// if necessary, and returns a Writer for it.
+func Create(dir string, cfg *WriterConfig) (*Writer, error) {
+	w := &Writer{
+		dir:      dir,
+		cmp:      cfg.Compare,
+		interval: cfg.IndexInterval,
+		// Like Hadoop, make sure the first key is always indexed.
+		lastIndexPos:  -1,
+		lastIndexSize: math.MinInt64,
+	}
+
+	dataCfg := cfg.WriterConfig
+	if dataCfg.KeyClass == "" {
+		dataCfg.KeyClass = sequencefile.BytesWritableClassName
+	}
+	if dataCfg.ValueClass == "" {
+		dataCfg.ValueClass = sequencefile.BytesWritableClassName
+	}
+
+	if w.interval <= 0 {
+		w.interval = DefaultIndexInterval
+	}
+
+	var err error
+	if w.cmp == nil {
+		w.cmp, err = sequencefile.NewRawComparator(dataCfg.KeyClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// These are only needed for Append, not AppendRaw.
+	w.keyClass, w.valueClass = dataCfg.KeyClass, dataCfg.ValueClass
+	w.keyWriter, _ = sequencefile.NewWritableWriter(dataCfg.KeyClass)
+	w.valueWriter, _ = sequencefile.NewWritableWriter(dataCfg.ValueClass)
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	dataFile, err := w.create(DataFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	dataCfg.Writer = dataFile
+	w.data, err = sequencefile.NewRawWriter(&dataCfg)
+	if err != nil {
+		w.Abort()
+		return nil, err
+	}
+
+	indexFile, err := w.create(IndexFileName)
+	if err != nil {
+		w.Abort()
+		return nil, err
+	}
+
+	w.index, err = sequencefile.NewRawWriter(&sequencefile.WriterConfig{
+		Writer:           indexFile,
+		KeyClass:         dataCfg.KeyClass,
+		ValueClass:       sequencefile.LongWritableClassName,
+		Compression:      sequencefile.BlockCompression,
+		CompressionCodec: sequencefile.ZlibCompression,
+		Rand:             dataCfg.Rand,
+	})
+	if err != nil {
+		w.Abort()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Append adds a key/value pair to the MapFile. The types of the key and value
+// must match the KeyClass and ValueClass of the WriterConfig, and the key must
+// not sort before the previous one.
+func (w *Writer) Append(key interface{}, value interface{}) error {
+	k, v, err := w.serialize(key, value)
+	if err != nil {
+		return err
+	}
+
+	return w.AppendRaw(k, v)
+}
+
+func (w *Writer) serialize(key interface{}, value interface{}) ([]byte, []byte, error) {
+	if w.keyWriter == nil {
+		return nil, nil, fmt.Errorf("Unknown writable class %s", w.keyClass)
+	} else if w.valueWriter == nil {
+		return nil, nil, fmt.Errorf("Unknown writable class %s", w.valueClass)
+	}
+
+	var kbuf, vbuf bytes.Buffer
+	if err := w.keyWriter(&kbuf, key); err != nil {
+		return nil, nil, err
+	}
+	if err := w.valueWriter(&vbuf, value); err != nil {
+		return nil, nil, err
+	}
+
+	return kbuf.Bytes(), vbuf.Bytes(), nil
+}
+
+// AppendRaw adds a serialized key/value pair to the MapFile, like
+// sequencefile.Writer.AppendRaw. The key must not sort before the previous
+// one.
+func (w *Writer) AppendRaw(key, value []byte) error {
+	if w.size > 0 && w.cmp(w.lastKey, key) > 0 {
+		return fmt.Errorf("mapfile: key out of order: record %d sorts before the one preceding it", w.size)
+	}
+
+	// Index the key if we're far enough past the last entry, and also in a
+	// different block.
+	pos := w.data.Position()
+	if w.size >= w.lastIndexSize+int64(w.interval) && pos > w.lastIndexPos {
+		if err := w.index.AppendRaw(key, longWritable(pos)); err != nil {
+			return err
+		}
+
+		w.lastIndexPos = pos
+		w.lastIndexSize = w.size
+	}
+
+	if err := w.data.AppendRaw(key, value); err != nil {
+		return err
+	}
+
+	w.lastKey = append(w.lastKey[:0], key...)
+	w.size++
+	return nil
+}
+
+// Close finishes writing the data and index files, and moves them into place.
+// If that fails, the files are removed.
+func (w *Writer) Close() error {
+	if err := w.finish(); err != nil {
+		return err
+	}
+
+	return w.commit()
+}
+
+// Abort stops writing the MapFile and removes the files written so far. It
+// does nothing after a successful Close.
+func (w *Writer) Abort() {
+	for _, f := range w.files {
+		f.Abort()
+	}
+}
+
+// create creates a file in the MapFile's directory, under a temporary name
+// until the MapFile is committed.
+func (w *Writer) create(name string) (*pending.File, error) {
+	f, err := pending.Create(filepath.Join(w.dir, "."+name+".tmp"), filepath.Join(w.dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	w.files = append(w.files, f)
+	return f, nil
+}
+
+// finish finishes writing the data and index files, which also closes them,
+// but leaves them under their temporary names. If that fails, the MapFile is
+// aborted.
+func (w *Writer) finish() error {
+	err := w.data.Close()
+	if err2 := w.index.Close(); err == nil {
+		err = err2
+	}
+
+	if err != nil {
+		w.Abort()
+	}
+
+	return err
+}
+
+// commit moves all the files into place, in the order they were created. They
+// are all closed first, so that nothing is moved unless everything was
+// written successfully.
+func (w *Writer) commit() error {
+	for _, f := range w.files {
+		if err := f.Close(); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+
+	for _, f := range w.files {
+		if err := f.Commit(); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+
+	return nil
+}
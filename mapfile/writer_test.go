@@ -0,0 +1,124 @@
+package mapfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/colinmarc/sequencefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	for _, compression := range compressions {
+		t.Run(compression.String(), func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "map")
+			cfg := &WriterConfig{
+				WriterConfig: sequencefile.WriterConfig{
+					KeyClass:   sequencefile.TextClassName,
+					ValueClass: sequencefile.IntWritableClassName,
+					BlockSize:  200,
+				},
+				IndexInterval: 5,
+			}
+			if compression != sequencefile.NoCompression {
+				cfg.Compression = compression
+				cfg.CompressionCodec = sequencefile.SnappyCompression
+			}
+
+			w, err := Create(dir, cfg)
+			require.NoError(t, err)
+			appendTestRecords(t, w.Append, 1000)
+			require.NoError(t, w.Close())
+
+			r, err := Open(dir)
+			require.NoError(t, err)
+			defer r.Close()
+
+			if compression == sequencefile.BlockCompression {
+				assert.Less(t, len(r.positions), 500/5, "there should be at most one index entry per block")
+			} else {
+				assert.Len(t, r.positions, 500/5)
+			}
+
+			for i := 1; i < len(r.positions); i++ {
+				assert.Greater(t, r.positions[i], r.positions[i-1])
+			}
+
+			for _, i := range []int{0, 998, 500, 2, 10, 12} {
+				v, err := r.Get(text(fmt.Sprintf("key-%04d", i)))
+				require.NoError(t, err, "key-%04d", i)
+				assert.Equal(t, int32(i), sequencefile.IntWritable(v))
+			}
+
+			_, err = r.Get(text("key-0011"))
+			assert.Equal(t, ErrNotFound, err)
+		})
+	}
+}
+
+func TestWriterOutOfOrder(t *testing.T) {
+	w, err := Create(t.TempDir(), &WriterConfig{
+		WriterConfig: sequencefile.WriterConfig{
+			KeyClass:   sequencefile.LongWritableClassName,
+			ValueClass: sequencefile.TextClassName,
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Append(int64(-1), "foo"))
+	require.NoError(t, w.Append(int64(5), "foo"))
+	require.NoError(t, w.Append(int64(5), "bar"), "equal keys should be allowed")
+	assert.Error(t, w.Append(int64(4), "baz"))
+	require.NoError(t, w.Append(int64(6), "baz"), "the writer should still be usable")
+	require.NoError(t, w.Close())
+}
+
+func TestWriterAbort(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "map")
+	w, err := Create(dir, &WriterConfig{
+		WriterConfig: sequencefile.WriterConfig{
+			KeyClass:   sequencefile.LongWritableClassName,
+			ValueClass: sequencefile.TextClassName,
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Append(int64(1), "foo"))
+	_, err = Open(dir)
+	assert.Error(t, err, "the MapFile shouldn't be visible before it's closed")
+
+	w.Abort()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestWriterClose(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "map")
+	w, err := CreateBloom(dir, &BloomWriterConfig{
+		WriterConfig: WriterConfig{
+			WriterConfig: sequencefile.WriterConfig{
+				KeyClass:   sequencefile.LongWritableClassName,
+				ValueClass: sequencefile.TextClassName,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Append(int64(1), "foo"))
+	require.NoError(t, w.Close())
+	w.Abort()
+
+	var names []string
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	assert.Equal(t, []string{BloomFileName, DataFileName, IndexFileName}, names,
+		"there should be no temporary files, and Abort shouldn't remove anything after Close")
+}
@@ -0,0 +1,241 @@
+package sequencefile
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+)
+
+// MergeOptions specifies how Merge orders records.
+type MergeOptions struct {
+	// Compare, if set, is used to order keys instead of the RawComparator for
+	// the key class of the input. The inputs must be sorted in the same order.
+	Compare RawComparator
+}
+
+// Merge reads the SequenceFiles in src, which must each already be sorted by
+// key, and writes all of their records to a new SequenceFile, written
+// according to dst, in order by key. Records with equal keys are written in
+// the order of the inputs they came from.
+//
+// The inputs are checked as they are read, and Merge returns an error if any
+// of them turns out not to be sorted. Records are copied as raw bytes, without
+// being deserialized.
+//
+// As with Sort, the key and value classes are taken from the input, and the
+// metadata too if dst.Metadata is nil. All of the inputs must have the same
+// key and value classes. The Writer is closed once all the records have been
+// written, which also closes dst.Writer if it implements io.Closer. If there's
+// an error, the output is incomplete, and dst.Writer is left open for the
+// caller to discard.
+func Merge(src []io.Reader, dst *WriterConfig, opts *MergeOptions) error {
+	if len(src) == 0 {
+		return fmt.Errorf("sequencefile: no files to merge")
+	}
+
+	readers, err := readHeaders(src)
+	if err != nil {
+		return err
+	}
+
+	header := readers[0].Header
+	var cmp RawComparator
+	if opts != nil {
+		cmp = opts.Compare
+	}
+	if cmp == nil {
+		cmp, err = NewRawComparator(header.KeyClassName)
+		if err != nil {
+			return err
+		}
+	}
+
+	sources := make([]recordSource, len(readers))
+	for i, r := range readers {
+		sources[i] = &sortedSource{Reader: r, cmp: cmp, index: i}
+	}
+
+	w, err := NewRawWriter(copyConfig(dst, header))
+	if err != nil {
+		return err
+	}
+
+	m := newMerger(cmp, sources)
+	for m.Scan() {
+		err = w.AppendRaw(m.Key(), m.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Err() != nil {
+		return m.Err()
+	}
+
+	return w.Close()
+}
+
+// A sortedSource wraps a Reader, returning an error if its keys are out of
+// order.
+type sortedSource struct {
+	*Reader
+	cmp   RawComparator
+	index int
+	prev  []byte
+	err   error
+}
+
+func (s *sortedSource) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	if s.Reader.Scan() {
+		key := s.Reader.Key()
+		if s.prev != nil && s.cmp(s.prev, key) > 0 {
+			s.err = fmt.Errorf("sequencefile: input %d is not sorted: a key at offset %d is less than the one before it",
+				s.index, s.Reader.Offset())
+			return false
+		}
+
+		s.prev = append(s.prev[:0], key...)
+		return true
+	}
+
+	return false
+}
+
+func (s *sortedSource) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	return s.Reader.Err()
+}
+
+// A recordSource is anything that can be scanned for serialized key/value
+// pairs, like a Reader.
+type recordSource interface {
+	Scan() bool
+	Key() []byte
+	Value() []byte
+	Err() error
+}
+
+// A merger does a k-way merge of sorted record sources. Records with equal
+// keys are returned in the order of their sources, so merging the sorted runs
+// of a stable sort keeps it stable.
+type merger struct {
+	cmp     RawComparator
+	sources []recordSource
+	heap    mergeHeap
+	started bool
+
+	current int
+	err     error
+}
+
+func newMerger(cmp RawComparator, sources []recordSource) *merger {
+	m := &merger{cmp: cmp, sources: sources, current: -1}
+	m.heap.m = m
+	return m
+}
+
+// Scan advances to the next record, in order.
+func (m *merger) Scan() bool {
+	if m.err != nil {
+		return false
+	}
+
+	if !m.started {
+		m.started = true
+		for i := range m.sources {
+			if m.advance(i) {
+				m.heap.indexes = append(m.heap.indexes, i)
+			}
+		}
+		heap.Init(&m.heap)
+	} else if m.current >= 0 {
+		if m.advance(m.current) {
+			heap.Fix(&m.heap, 0)
+		} else {
+			heap.Pop(&m.heap)
+		}
+	}
+
+	if m.err != nil || len(m.heap.indexes) == 0 {
+		m.current = -1
+		return false
+	}
+
+	m.current = m.heap.indexes[0]
+	return true
+}
+
+// advance scans the source at index i, returning true if it has another
+// record.
+func (m *merger) advance(i int) bool {
+	src := m.sources[i]
+	if src.Scan() {
+		return true
+	}
+
+	if src.Err() != nil && m.err == nil {
+		m.err = src.Err()
+	}
+
+	return false
+}
+
+// Source returns the index of the source of the current record.
+func (m *merger) Source() int {
+	return m.current
+}
+
+func (m *merger) Key() []byte {
+	return m.sources[m.current].Key()
+}
+
+func (m *merger) Value() []byte {
+	return m.sources[m.current].Value()
+}
+
+func (m *merger) Err() error {
+	return m.err
+}
+
+// A mergeHeap is a heap of the indexes of sources, ordered by their current
+// keys and then by index.
+type mergeHeap struct {
+	m       *merger
+	indexes []int
+}
+
+func (h *mergeHeap) Len() int {
+	return len(h.indexes)
+}
+
+func (h *mergeHeap) Less(i, j int) bool {
+	a, b := h.indexes[i], h.indexes[j]
+	c := h.m.cmp(h.m.sources[a].Key(), h.m.sources[b].Key())
+	if c != 0 {
+		return c < 0
+	}
+
+	return a < b
+}
+
+func (h *mergeHeap) Swap(i, j int) {
+	h.indexes[i], h.indexes[j] = h.indexes[j], h.indexes[i]
+}
+
+func (h *mergeHeap) Push(x interface{}) {
+	h.indexes = append(h.indexes, x.(int))
+}
+
+func (h *mergeHeap) Pop() interface{} {
+	n := len(h.indexes)
+	x := h.indexes[n-1]
+	h.indexes = h.indexes[:n-1]
+	return x
+}
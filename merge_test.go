@@ -0,0 +1,77 @@
+package sequencefile
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	src := []io.Reader{
+		writeLongs(t, []int64{-5, 1, 3, 3, 10}, "a"),
+		writeLongs(t, []int64{}, "b"),
+		writeLongs(t, []int64{0, 3, 11}, "c"),
+		writeLongs(t, []int64{-10, 1, 100}, "d"),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Merge(src, &WriterConfig{Writer: &out}, nil))
+
+	r := NewReader(&out)
+	require.NoError(t, r.ReadHeader())
+	assert.Equal(t, LongWritableClassName, r.Header.KeyClassName)
+	assert.Equal(t, map[string]string{"input": "a"}, r.Header.Metadata)
+
+	var keys []int64
+	var values []string
+	for r.Scan() {
+		keys = append(keys, LongWritable(r.Key()))
+		values = append(values, Text(r.Value()))
+	}
+
+	require.NoError(t, r.Err())
+	assert.Equal(t, []int64{-10, -5, 0, 1, 1, 3, 3, 3, 10, 11, 100}, keys)
+	assert.Equal(t, []string{"d", "a", "c", "a", "d", "a", "a", "c", "a", "c", "d"}, values,
+		"equal keys should be in input order")
+}
+
+func TestMergeUnsorted(t *testing.T) {
+	src := []io.Reader{
+		writeLongs(t, []int64{1, 2, 3}, "a"),
+		writeLongs(t, []int64{1, 5, 4}, "b"),
+	}
+
+	out := &closeRecorder{}
+	err := Merge(src, &WriterConfig{Writer: out}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input 1 is not sorted")
+	assert.False(t, out.closed, "the output shouldn't be closed after an error")
+}
+
+func TestMergeCustomComparator(t *testing.T) {
+	reverse := func(a, b []byte) int {
+		return compareLong(b, a)
+	}
+
+	src := []io.Reader{
+		writeLongs(t, []int64{3, 2, 1}, "a"),
+		writeLongs(t, []int64{4, 2, 0}, "b"),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Merge(src, &WriterConfig{Writer: &out}, &MergeOptions{Compare: reverse}))
+
+	r := NewReader(&out)
+	require.NoError(t, r.ReadHeader())
+
+	var keys []int64
+	for r.Scan() {
+		keys = append(keys, LongWritable(r.Key()))
+	}
+
+	require.NoError(t, r.Err())
+	assert.Equal(t, []int64{4, 3, 2, 2, 1, 0}, keys)
+}
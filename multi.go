@@ -0,0 +1,339 @@
+package sequencefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A MultiReader reads key/value pairs from a set of SequenceFiles, such as
+// the part files in the output directory of a Hadoop job, as if they were a
+// single file.
+//
+// By default, files are read one after another, in order. If Parallelism is
+// set, several files are read at once and their records are interleaved.
+type MultiReader struct {
+	// Header is the header of the first file. All the files have the same key
+	// and value classes, but may differ otherwise.
+	Header Header
+
+	// Parallelism is the number of files to read at once. It must be set before
+	// the first call to Scan. Values less than two mean the files are read
+	// sequentially.
+	Parallelism int
+
+	paths   []string
+	started bool
+
+	// Sequential reading.
+	next    int
+	current *Reader
+
+	// Parallel reading.
+	records  chan multiRecord
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+
+	path   string
+	offset int64
+	key    []byte
+	value  []byte
+	err    error
+}
+
+type multiRecord struct {
+	path       string
+	offset     int64
+	key, value []byte
+	err        error
+}
+
+// OpenDir opens all the SequenceFiles in a directory, skipping files whose
+// names start with '_' or '.', like _SUCCESS or the .crc files Hadoop writes
+// alongside each part. Subdirectories are skipped as well.
+func OpenDir(dir string) (*MultiReader, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || isHiddenFile(e.Name()) {
+			continue
+		}
+
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+
+	return NewMultiReader(paths)
+}
+
+// OpenGlob opens all the SequenceFiles matching a pattern, as understood by
+// filepath.Glob. As with OpenDir, files starting with '_' or '.' are skipped.
+func OpenGlob(pattern string) (*MultiReader, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, path := range matches {
+		if isHiddenFile(filepath.Base(path)) {
+			continue
+		}
+
+		if info, err := os.Stat(path); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	return NewMultiReader(paths)
+}
+
+// NewMultiReader returns a MultiReader for the given files, which are read in
+// sorted order. The header of every file is read immediately, to check that
+// they all have the same key and value classes.
+func NewMultiReader(paths []string) (*MultiReader, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("sequencefile: no files to read")
+	}
+
+	paths = append([]string(nil), paths...)
+	sort.Strings(paths)
+
+	m := &MultiReader{paths: paths}
+	for i, path := range paths {
+		r, err := Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		r.Close()
+
+		if i == 0 {
+			m.Header = r.Header
+		} else if err := checkCompatible(m.Header, r.Header); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return m, nil
+}
+
+func isHiddenFile(name string) bool {
+	return strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".")
+}
+
+func checkCompatible(a, b Header) error {
+	if a.KeyClassName != b.KeyClassName {
+		return fmt.Errorf("sequencefile: mismatched key class: %s != %s", b.KeyClassName, a.KeyClassName)
+	} else if a.ValueClassName != b.ValueClassName {
+		return fmt.Errorf("sequencefile: mismatched value class: %s != %s", b.ValueClassName, a.ValueClassName)
+	}
+
+	return nil
+}
+
+// Paths returns the files being read, in order.
+func (m *MultiReader) Paths() []string {
+	return m.paths
+}
+
+// Scan advances to the next record, reading the key and value into memory.
+// These can then be obtained by calling Key and Value. If the end of the last
+// file is reached, or there is an error, Scan will return false.
+func (m *MultiReader) Scan() bool {
+	if m.err != nil {
+		return false
+	}
+
+	if !m.started {
+		m.started = true
+		if m.Parallelism > 1 {
+			m.startWorkers()
+		}
+	}
+
+	if m.records != nil {
+		return m.scanParallel()
+	}
+
+	return m.scanSequential()
+}
+
+func (m *MultiReader) scanSequential() bool {
+	for {
+		if m.current == nil {
+			if m.next >= len(m.paths) {
+				return false
+			}
+
+			path := m.paths[m.next]
+			m.next++
+
+			r, err := Open(path)
+			if err != nil {
+				m.err = fmt.Errorf("%s: %s", path, err)
+				return false
+			}
+
+			m.current = r
+			m.path = path
+		}
+
+		if m.current.Scan() {
+			m.offset = m.current.Offset()
+			m.key = m.current.Key()
+			m.value = m.current.Value()
+			return true
+		}
+
+		m.current.Close()
+		if err := m.current.Err(); err != nil {
+			m.err = fmt.Errorf("%s: %s", m.path, err)
+			m.current = nil
+			return false
+		}
+
+		m.current = nil
+	}
+}
+
+func (m *MultiReader) startWorkers() {
+	paths := make(chan string, len(m.paths))
+	for _, path := range m.paths {
+		paths <- path
+	}
+	close(paths)
+
+	m.records = make(chan multiRecord, 64*m.Parallelism)
+	m.done = make(chan struct{})
+	for i := 0; i < m.Parallelism; i++ {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			for path := range paths {
+				if !m.readFile(path) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		m.wg.Wait()
+		close(m.records)
+	}()
+}
+
+// readFile sends all the records in a file to the records channel, returning
+// false if the MultiReader was closed.
+func (m *MultiReader) readFile(path string) bool {
+	send := func(rec multiRecord) bool {
+		select {
+		case m.records <- rec:
+			return true
+		case <-m.done:
+			return false
+		}
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		return send(multiRecord{path: path, err: err})
+	}
+	defer r.Close()
+
+	for r.Scan() {
+		rec := multiRecord{
+			path:   path,
+			offset: r.Offset(),
+			key:    append([]byte(nil), r.Key()...),
+			value:  append([]byte(nil), r.Value()...),
+		}
+
+		if !send(rec) {
+			return false
+		}
+	}
+
+	if r.Err() != nil {
+		return send(multiRecord{path: path, err: r.Err()})
+	}
+
+	return true
+}
+
+func (m *MultiReader) scanParallel() bool {
+	rec, ok := <-m.records
+	if !ok {
+		return false
+	} else if rec.err != nil {
+		m.err = fmt.Errorf("%s: %s", rec.path, rec.err)
+		m.stopWorkers()
+		return false
+	}
+
+	m.path = rec.path
+	m.offset = rec.offset
+	m.key = rec.key
+	m.value = rec.value
+	return true
+}
+
+func (m *MultiReader) stopWorkers() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+	})
+}
+
+// Key returns the key for the current record. The byte slice will be reused
+// after the next call to Scan.
+func (m *MultiReader) Key() []byte {
+	return m.key
+}
+
+// Value returns the value for the current record. The byte slice will be
+// reused after the next call to Scan.
+func (m *MultiReader) Value() []byte {
+	return m.value
+}
+
+// Path returns the file that the current record came from.
+func (m *MultiReader) Path() string {
+	return m.path
+}
+
+// Offset returns the offset of the current record within its file, as
+// returned by Reader.Offset.
+func (m *MultiReader) Offset() int64 {
+	return m.offset
+}
+
+// Err returns the first non-EOF error reached while scanning.
+func (m *MultiReader) Err() error {
+	return m.err
+}
+
+// Close closes any open files and stops any background reading.
+func (m *MultiReader) Close() error {
+	if m.current != nil {
+		m.current.Close()
+		m.current = nil
+	}
+
+	if m.records != nil {
+		m.stopWorkers()
+		m.wg.Wait()
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package sequencefile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenDir(t *testing.T) {
+	dir := t.TempDir()
+	writeParts(t, dir, 3, 100)
+
+	m, err := OpenDir(dir)
+	require.NoError(t, err)
+	defer m.Close()
+
+	assert.Len(t, m.Paths(), 3)
+	assert.Equal(t, LongWritableClassName, m.Header.KeyClassName)
+
+	n := int64(0)
+	lastOffset := int64(-1)
+	for m.Scan() {
+		require.Equal(t, n, LongWritable(m.Key()))
+		assert.Equal(t, "foo", Text(m.Value()))
+		assert.Equal(t, filepath.Join(dir, fmt.Sprintf("part-r-%05d", n/100)), m.Path())
+		if n%100 != 0 {
+			assert.Greater(t, m.Offset(), lastOffset)
+		}
+
+		lastOffset = m.Offset()
+		n++
+	}
+
+	require.NoError(t, m.Err())
+	assert.Equal(t, int64(300), n)
+}
+
+func TestOpenGlobParallel(t *testing.T) {
+	dir := t.TempDir()
+	writeParts(t, dir, 5, 100)
+
+	m, err := OpenGlob(filepath.Join(dir, "part-*"))
+	require.NoError(t, err)
+	defer m.Close()
+
+	m.Parallelism = 3
+	var keys []int64
+	for m.Scan() {
+		keys = append(keys, LongWritable(m.Key()))
+
+		// Seek to the record in its file, to check the path and offset.
+		r, err := Open(m.Path())
+		require.NoError(t, err)
+		_, err = r.Seek(m.Offset(), io.SeekStart)
+		require.NoError(t, err)
+		require.True(t, r.Scan())
+		assert.Equal(t, m.Key(), r.Key())
+		r.Close()
+	}
+
+	require.NoError(t, m.Err())
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	require.Len(t, keys, 500)
+	for i, k := range keys {
+		assert.Equal(t, int64(i), k)
+	}
+}
+
+func TestMultiReaderMismatched(t *testing.T) {
+	dir := t.TempDir()
+	writeParts(t, dir, 2, 10)
+
+	buf := assertWrite(t, &WriterConfig{
+		KeyClass:   TextClassName,
+		ValueClass: TextClassName,
+	}, []writePair{{"foo", "bar"}})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "part-r-00002"), buf, 0644))
+
+	_, err := OpenDir(dir)
+	assert.Error(t, err)
+
+	_, err = OpenGlob(filepath.Join(dir, "nope-*"))
+	assert.Error(t, err)
+}
+
+func TestMultiReaderCloseEarly(t *testing.T) {
+	dir := t.TempDir()
+	writeParts(t, dir, 4, 1000)
+
+	m, err := OpenDir(dir)
+	require.NoError(t, err)
+
+	m.Parallelism = 2
+	require.True(t, m.Scan())
+	require.NoError(t, m.Close())
+}
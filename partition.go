@@ -0,0 +1,87 @@
+package sequencefile
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A PartitionedWriter writes key/value pairs to a set of Writers, choosing one
+// for each key in the same way as Hadoop's HashPartitioner. Writing the output
+// of a job with a PartitionedWriter produces part files with the same keys as
+// those produced by Hadoop with the same number of reduce tasks, which matters
+// for things like map-side joins.
+type PartitionedWriter struct {
+	writers   []*Writer
+	keyClass  string
+	keyWriter WritableWriter
+	hash      WritableHash
+}
+
+// NewPartitionedWriter returns a PartitionedWriter that writes to the given
+// Writers, in order, such that the Writer at index i gets the keys for
+// partition i. All of the Writers must have the same KeyClass, and it must be
+// one that this package knows how to hash.
+func NewPartitionedWriter(writers []*Writer) (*PartitionedWriter, error) {
+	if len(writers) == 0 {
+		return nil, fmt.Errorf("sequencefile: no writers to partition between")
+	}
+
+	keyClass := writers[0].cfg.KeyClass
+	for _, w := range writers[1:] {
+		if w.cfg.KeyClass != keyClass {
+			return nil, fmt.Errorf("sequencefile: mismatched key class: %s != %s", w.cfg.KeyClass, keyClass)
+		}
+	}
+
+	hash, err := NewWritableHash(keyClass)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartitionedWriter{
+		writers:   writers,
+		keyClass:  keyClass,
+		keyWriter: writers[0].keyWriter,
+		hash:      hash,
+	}, nil
+}
+
+// Partition returns the index of the Writer that a serialized key is written
+// to.
+func (p *PartitionedWriter) Partition(key []byte) int {
+	return HashPartition(p.hash(key), len(p.writers))
+}
+
+// Append adds a key/value pair to the Writer for the key's partition. The
+// types of the key and value must match the KeyClass and ValueClass of the
+// Writers.
+func (p *PartitionedWriter) Append(key interface{}, value interface{}) error {
+	if p.keyWriter == nil {
+		return fmt.Errorf("Unknown writable class %s", p.keyClass)
+	}
+
+	var kbuf bytes.Buffer
+	if err := p.keyWriter(&kbuf, key); err != nil {
+		return err
+	}
+
+	return p.writers[p.Partition(kbuf.Bytes())].Append(key, value)
+}
+
+// AppendRaw adds a serialized key/value pair to the Writer for the key's
+// partition, like Writer.AppendRaw.
+func (p *PartitionedWriter) AppendRaw(key, value []byte) error {
+	return p.writers[p.Partition(key)].AppendRaw(key, value)
+}
+
+// Close closes all of the Writers, returning the first error encountered.
+func (p *PartitionedWriter) Close() error {
+	var ret error
+	for _, w := range p.writers {
+		if err := w.Close(); err != nil && ret == nil {
+			ret = err
+		}
+	}
+
+	return ret
+}
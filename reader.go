@@ -11,6 +11,10 @@ import (
 	"os"
 )
 
+// DefaultMaxLength is the default limit on the length of a record, or a
+// section of a block, that a Reader will read. See SetMaxLength.
+const DefaultMaxLength = 256 * 1024 * 1024
+
 // A Reader reads key/value pairs from a SequenceFile input stream.
 //
 // A reader is valid at any key or block offset; it's safe to start in the
@@ -23,9 +27,28 @@ type Reader struct {
 	Header          Header
 	syncMarkerBytes []byte
 
-	reader io.Reader
-	closed bool
-	err    error
+	reader    io.Reader
+	file      io.Closer
+	size      int64
+	maxLength int
+	follow    *followReader
+	in        readerHelper
+	closed    bool
+	err       error
+
+	headerEnd  int64
+	start      int64
+	resyncFrom int64
+	recovery   bool
+	onCorrupt  func(CorruptRange)
+	skipped    []CorruptRange
+	synced     bool
+
+	stats ReaderStats
+
+	// If held is set, the current record was pushed back with unscan, and
+	// hasn't been returned by Scan yet.
+	held bool
 
 	compression  Compression
 	codec        CompressionCodec
@@ -44,9 +67,18 @@ func Open(path string) (*Reader, error) {
 		return nil, err
 	}
 
-	r := NewReader(bufio.NewReader(f))
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r := NewReader(&bufferedFile{bufio.NewReader(f), f})
+	r.file = f
+	r.size = info.Size()
 	err = r.ReadHeader()
 	if err != nil {
+		f.Close()
 		return nil, err
 	}
 
@@ -57,7 +89,7 @@ func Open(path string) (*Reader, error) {
 // io.Reader is positioned at the start of a file, you should immediately call
 // ReadHeader to read through the header.
 func NewReader(r io.Reader) *Reader {
-	return &Reader{reader: r}
+	return &Reader{reader: r, in: readerHelper{r: r}, maxLength: DefaultMaxLength}
 }
 
 // New returns a new Reader for a SequenceFile, reading data from r. Normally,
@@ -72,11 +104,39 @@ func NewReaderCompression(r io.Reader, compression Compression, codec Compressio
 	return rd
 }
 
+// SetMaxLength sets a limit on the length of a single record, or a single
+// section of a block, that the Reader will read. Lengths are read from the
+// file itself, so this stops a corrupt length from causing a huge allocation;
+// longer records are treated as corrupt instead.
+//
+// If the size of the input is known, as it is for files opened with Open or
+// inputs like a bytes.Reader, lengths are checked against the remaining input
+// instead, and the limit only applies to decompressed data. The default is
+// DefaultMaxLength.
+func (r *Reader) SetMaxLength(n int) {
+	r.maxLength = n
+}
+
 // Scan advances the reader to the start of the next record, reading the key
 // and value into memory. These can then be obtained by calling Key and Value.
 // If the end of the file is reached, or there is an error, Scan will return
 // false.
 func (r *Reader) Scan() bool {
+	if r.held {
+		r.held = false
+		return true
+	}
+
+	return r.scan()
+}
+
+// unscan pushes the current record back, so that the next call to Scan returns
+// it again instead of advancing. Seeking or resetting the Reader discards it.
+func (r *Reader) unscan() {
+	r.held = true
+}
+
+func (r *Reader) scan() bool {
 	if r.compression == BlockCompression {
 		return r.scanBlock()
 	} else {
@@ -84,20 +144,133 @@ func (r *Reader) Scan() bool {
 	}
 }
 
+// Seek implements io.Seeker, seeking the underlying input stream. The new
+// offset must be the start of a record, or for block-compressed files, the
+// start of a block (the offset of the sync marker preceding it). The
+// underlying reader must implement io.Seeker, as it does for Readers created
+// with Open.
+//
+// Like all offsets used by the Reader, offsets are relative to the position
+// of the input stream when the Reader was created.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.reader.(io.Seeker)
+	if !ok {
+		return 0, errors.New("sequencefile: the underlying reader doesn't support seeking")
+	}
+
+	// The underlying stream is ahead of our position by however many bytes
+	// were pushed back.
+	current := r.in.pos + int64(len(r.in.pending))
+	var err error
+	switch whence {
+	case io.SeekStart:
+		_, err = seeker.Seek(offset-current, io.SeekCurrent)
+	case io.SeekCurrent:
+		offset += r.in.pos
+		_, err = seeker.Seek(offset-current, io.SeekCurrent)
+	case io.SeekEnd:
+		var abs, end int64
+		abs, err = seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			end, err = seeker.Seek(offset, io.SeekEnd)
+			offset = end - (abs - current)
+		}
+	default:
+		err = errors.New("sequencefile: invalid whence")
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	r.Reset()
+	r.in.pos = offset
+	r.start = offset
+	r.closed = false
+	r.err = nil
+	return offset, nil
+}
+
+// Sync seeks to the first sync marker at or after offset, like Hadoop's
+// SequenceFile.Reader.sync, so that the next call to Scan returns the first
+// record after it. If offset is within the header, the Reader is positioned at
+// the first record instead. It returns io.EOF if there are no sync markers
+// after offset.
+//
+// Sync requires that the sync marker be known, which it is once the header
+// has been read. The underlying reader must implement io.Seeker.
+func (r *Reader) Sync(offset int64) error {
+	if r.syncMarkerBytes == nil {
+		return errors.New("sequencefile: the sync marker is unknown")
+	}
+
+	if offset <= r.headerEnd {
+		_, err := r.Seek(r.headerEnd, io.SeekStart)
+		return err
+	}
+
+	_, err := r.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = r.scanToSync(nil)
+	if err != nil {
+		return err
+	}
+
+	r.synced = true
+	r.start = r.in.pos - SyncSize - 4
+	return nil
+}
+
+// Offset returns the offset in the input of the current record, or for
+// block-compressed files, of the start of the block containing it. It can be
+// passed to Seek to return to the record (or block). Before the first call to
+// Scan, it returns the offset of the first record.
+func (r *Reader) Offset() int64 {
+	return r.start
+}
+
 // Reset resets the internal state of the reader, but maintains compression
 // settings and header information. You should call Reset if you seek the
 // underlying reader, but should create an entirely new Reader if you are
 // starting a different file.
 func (r *Reader) Reset() {
 	r.clear()
+	r.in.reset(r.reader)
 	r.block = blockReader{}
+	r.synced = false
+	r.held = false
+}
+
+// Close closes the underlying file, if the Reader was created with Open. It is
+// a no-op otherwise.
+func (r *Reader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	return r.file.Close()
 }
 
 // Err returns the first non-EOF error reached while scanning.
 func (r *Reader) Err() error {
+	// A partial record is expected when a followed file stops while it's still
+	// being written, but not once it's complete.
+	if r.Truncated() && !r.follow.renamed {
+		return nil
+	}
+
 	return r.err
 }
 
+// Truncated returns true if a Reader created with Follow stopped partway
+// through a record or block, and discarded it.
+func (r *Reader) Truncated() bool {
+	return r.follow != nil && r.follow.stopped && errors.Is(r.err, io.ErrUnexpectedEOF)
+}
+
 // Key returns the key for the current record. The byte slice will be reused
 // after the next call to Scan.
 func (r *Reader) Key() []byte {
@@ -111,17 +284,28 @@ func (r *Reader) Value() []byte {
 }
 
 func (r *Reader) scanRecord() bool {
-	if r.closed {
-		return false
+	for !r.closed {
+		err := r.readRecord()
+		if err == nil {
+			return true
+		} else if err == io.EOF {
+			return false
+		} else if !r.recover(err) {
+			return false
+		}
 	}
 
+	return false
+}
+
+func (r *Reader) readRecord() error {
+	r.beginRecord()
 	r.clear()
 	b, err := r.consume(4)
-	if err == io.EOF {
-		return false
+	if err == io.EOF && r.in.pos == r.start {
+		return io.EOF
 	} else if err != nil {
-		r.close(err)
-		return false
+		return unexpectedEOF(err)
 	}
 
 	// Length -1 means a sync marker (the length is obnoxiously encoded as a cast
@@ -130,52 +314,49 @@ func (r *Reader) scanRecord() bool {
 	if totalLength == -1 {
 		err = r.checkSync()
 		if err != nil {
-			r.close(err)
-			return false
+			return unexpectedEOF(err)
 		}
 
-		return r.scanRecord()
-	} else if totalLength < 8 {
-		r.close(fmt.Errorf("sequencefile: invalid record length: %d", totalLength))
-		return false
+		return r.readRecord()
+	} else if totalLength < 0 {
+		return fmt.Errorf("sequencefile: invalid record length: %d", totalLength)
 	}
 
+	return unexpectedEOF(r.readRecordBody(totalLength))
+}
+
+func (r *Reader) readRecordBody(totalLength int) error {
 	r.clear()
-	b, err = r.consume(4)
+	b, err := r.consume(4)
 	if err != nil {
-		r.close(err)
-		return false
+		return err
 	}
 
 	keyLength := int(int32(binary.BigEndian.Uint32(b)))
 	valueLength := totalLength - keyLength
-	if keyLength < 4 {
-		r.close(fmt.Errorf("sequencefile: invalid key length: %d", keyLength))
-		return false
+	if keyLength < 0 || valueLength < 0 {
+		return fmt.Errorf("sequencefile: invalid key length: %d", keyLength)
 	}
 
 	r.clear()
 	r.key, err = r.consume(keyLength)
 	if err != nil {
-		r.close(err)
-		return false
+		return err
 	}
 
 	if r.compression == RecordCompression {
 		r.value, err = r.consumeCompressed(valueLength)
 		if err != nil {
-			r.close(err)
-			return false
+			return err
 		}
 	} else {
 		r.value, err = r.consume(valueLength)
 		if err != nil {
-			r.close(err)
-			return false
+			return err
 		}
 	}
 
-	return true
+	return nil
 }
 
 func (r *Reader) checkSync() error {
@@ -194,14 +375,20 @@ func (r *Reader) checkSync() error {
 		return errors.New("sequencefile: invalid sync marker")
 	}
 
+	r.stats.SyncMarkers++
 	return nil
 }
 
 // consume reads some bytes off the input stream, and returns a bite slice that
 // is only valid until the next call to clear.
 func (r *Reader) consume(n int) ([]byte, error) {
+	err := r.checkLength(n)
+	if err != nil {
+		return nil, err
+	}
+
 	off := r.buf.Len()
-	_, err := io.CopyN(&r.buf, r.reader, int64(n))
+	_, err = io.CopyN(&r.buf, &r.in, int64(n))
 	if err != nil {
 		return nil, err
 	}
@@ -210,24 +397,73 @@ func (r *Reader) consume(n int) ([]byte, error) {
 }
 
 func (r *Reader) consumeCompressed(n int) ([]byte, error) {
-	lr := &io.LimitedReader{R: r.reader, N: int64(n)}
+	err := r.checkLength(n)
+	if err != nil {
+		return nil, err
+	}
+
+	lr := &io.LimitedReader{R: &r.in, N: int64(n)}
 	d, err := r.getDecompressor(lr)
 	if err != nil {
 		return nil, err
 	}
 
+	// Read up to one byte past the limit, to check if there's more.
 	off := r.buf.Len()
 	r.buf.Grow(n)
-	_, err = r.buf.ReadFrom(d)
+	dr := &io.LimitedReader{R: d, N: int64(r.maxLength) + 1}
+	_, err = r.buf.ReadFrom(dr)
 	if err != nil {
 		return nil, err
+	} else if dr.N == 0 {
+		return nil, fmt.Errorf("sequencefile: decompressed length exceeds the maximum of %d", r.maxLength)
 	} else if lr.N > 0 {
 		return nil, io.ErrUnexpectedEOF
 	}
 
+	r.stats.CompressedBytes += int64(n)
+	r.stats.UncompressedBytes += int64(r.buf.Len() - off)
 	return r.buf.Bytes()[off:r.buf.Len()], nil
 }
 
+// checkLength checks a length read from the input before it's used to read
+// (and allocate) that many bytes. If the length runs past the end of the
+// input, it returns the same error that reading would.
+func (r *Reader) checkLength(n int) error {
+	if remaining, ok := r.remaining(); ok {
+		if remaining == 0 && n > 0 {
+			return io.EOF
+		} else if int64(n) > remaining {
+			return io.ErrUnexpectedEOF
+		}
+	} else if n > r.maxLength {
+		return fmt.Errorf("sequencefile: length %d exceeds the maximum of %d", n, r.maxLength)
+	}
+
+	return nil
+}
+
+// remaining returns the number of bytes left in the input, if it's known.
+func (r *Reader) remaining() (int64, bool) {
+	if r.size > 0 {
+		return r.size - r.in.pos, true
+	} else if l, ok := r.reader.(interface{ Len() int }); ok {
+		return int64(l.Len()) + int64(len(r.in.pending)), true
+	}
+
+	return 0, false
+}
+
+// unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF, for use once we're
+// partway through a record or block.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
 func (r *Reader) clear() {
 	r.buf.Reset()
 }
@@ -253,7 +489,15 @@ func (r *Reader) getDecompressor(src io.Reader) (io.Reader, error) {
 		}
 	}
 
-	return r.decompressor, err
+	if err != nil {
+		// The constructors return a typed nil on error, and a decompressor that
+		// failed to reset is in an unknown state, so start again with the next
+		// block.
+		r.decompressor = nil
+		return nil, err
+	}
+
+	return r.decompressor, nil
 }
 
 func (r *Reader) close(err error) {
@@ -262,5 +506,6 @@ func (r *Reader) close(err error) {
 	r.clear()
 	if r.decompressor != nil {
 		r.decompressor.Close()
+		r.decompressor = nil
 	}
 }
@@ -0,0 +1,110 @@
+package sequencefile
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+// A Reader wrapper that:
+// - Keeps track of how many bytes have been read.
+// - Optionally keeps a copy of what was read, to search for sync markers.
+// - Allows bytes to be pushed back onto the front of the stream.
+type readerHelper struct {
+	r   io.Reader
+	pos int64
+
+	recording    bool
+	recorded     bytes.Buffer
+	recordedFrom int64
+	pending      []byte
+}
+
+func (h *readerHelper) Read(b []byte) (n int, err error) {
+	if len(h.pending) > 0 {
+		n = copy(b, h.pending)
+		h.pending = h.pending[n:]
+	} else {
+		n, err = h.r.Read(b)
+	}
+
+	h.pos += int64(n)
+	if h.recording {
+		h.recorded.Write(b[:n])
+	}
+
+	return n, err
+}
+
+func (h *readerHelper) ReadByte() (byte, error) {
+	var b byte
+	var err error
+	if len(h.pending) > 0 {
+		b = h.pending[0]
+		h.pending = h.pending[1:]
+	} else if br, ok := h.r.(io.ByteReader); ok {
+		b, err = br.ReadByte()
+	} else {
+		var buf [1]byte
+		_, err = io.ReadFull(h.r, buf[:])
+		b = buf[0]
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	h.pos++
+	if h.recording {
+		h.recorded.WriteByte(b)
+	}
+
+	return b, nil
+}
+
+// startRecording discards anything previously recorded, and starts keeping a
+// copy of every byte read.
+func (h *readerHelper) startRecording() {
+	h.recording = true
+	h.recorded.Reset()
+	h.recordedFrom = h.pos
+}
+
+// unread pushes b back onto the front of the stream, so that it will be
+// returned by subsequent reads.
+func (h *readerHelper) unread(b []byte) {
+	pending := make([]byte, 0, len(b)+len(h.pending))
+	pending = append(pending, b...)
+	h.pending = append(pending, h.pending...)
+	h.pos -= int64(len(b))
+}
+
+// reset points the helper at a new underlying reader, discarding any pending
+// or recorded data. The position is left as is.
+func (h *readerHelper) reset(r io.Reader) {
+	h.r = r
+	h.pending = nil
+	h.recorded.Reset()
+}
+
+// A bufferedFile is a buffered reader for a file that can still be seeked.
+type bufferedFile struct {
+	*bufio.Reader
+	f *os.File
+}
+
+func (b *bufferedFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent {
+		// The file itself is ahead of us by however much is buffered.
+		offset -= int64(b.Buffered())
+	}
+
+	n, err := b.f.Seek(offset, whence)
+	if err != nil {
+		return n, err
+	}
+
+	b.Reader.Reset(b.f)
+	return n, nil
+}
@@ -1,6 +1,8 @@
 package sequencefile
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"testing"
 
@@ -143,3 +145,76 @@ func testFileSpec(t *testing.T, r *Reader, spec fileSpec) {
 	assert.Equal(t, "Alice", string(BytesWritable(r.Key())), "The key should be correct")
 	assert.Equal(t, "Practice", string(BytesWritable(r.Value())), "The value should be correct")
 }
+
+func TestReadSmallRecords(t *testing.T) {
+	buf := assertWrite(t,
+		&WriterConfig{
+			KeyClass:   NullWritableClassName,
+			ValueClass: BooleanWritableClassName,
+		},
+		[]writePair{{nil, true}, {nil, false}},
+	)
+
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+
+	require.True(t, r.Scan())
+	assert.Empty(t, r.Key())
+	assert.True(t, BooleanWritable(r.Value()))
+	require.True(t, r.Scan())
+	assert.False(t, BooleanWritable(r.Value()))
+	assert.False(t, r.Scan())
+	assert.NoError(t, r.Err())
+}
+
+func TestSeekAndSync(t *testing.T) {
+	compressions := []compressionSpec{
+		{NoCompression, 0},
+		{RecordCompression, GzipCompression},
+		{BlockCompression, GzipCompression},
+	}
+
+	for _, cmp := range compressions {
+		buf := writeCorruptible(t, cmp, 200)
+		path := t.TempDir() + "/test.sequencefile"
+		require.NoError(t, os.WriteFile(path, buf, 0644))
+
+		r, err := Open(path)
+		require.NoError(t, err)
+
+		// Sync into the middle of the file.
+		require.NoError(t, r.Sync(int64(len(buf)/2)))
+		require.True(t, r.Scan())
+		mid := LongWritable(r.Key())
+		offset := r.Offset()
+		assert.Greater(t, mid, int64(50))
+		assert.Less(t, mid, int64(150))
+		assert.LessOrEqual(t, offset, int64(len(buf)))
+
+		// Seeking back to the offset should return the same record (or for
+		// block compression, the start of the block).
+		require.True(t, r.Scan())
+		_, err = r.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		require.True(t, r.Scan())
+		if cmp.compression == BlockCompression {
+			assert.LessOrEqual(t, LongWritable(r.Key()), mid)
+		} else {
+			assert.Equal(t, mid, LongWritable(r.Key()))
+		}
+
+		// Syncing to the start should return the first record.
+		require.NoError(t, r.Sync(0))
+		require.True(t, r.Scan())
+		assert.Equal(t, int64(0), LongWritable(r.Key()))
+
+		// There are no sync markers at the very end.
+		assert.Equal(t, io.EOF, r.Sync(int64(len(buf)-5)))
+
+		_, err = r.Seek(0, io.SeekEnd)
+		require.NoError(t, err)
+		assert.False(t, r.Scan())
+		assert.NoError(t, r.Err())
+		require.NoError(t, r.Close())
+	}
+}
@@ -0,0 +1,147 @@
+package sequencefile
+
+import (
+	"bytes"
+	"io"
+)
+
+// A CorruptRange describes a section of the input stream that was skipped
+// because it couldn't be decoded. Offsets are relative to the position of the
+// input stream when the Reader was created.
+type CorruptRange struct {
+	// Start is the offset of the start of the record or block that failed to
+	// decode.
+	Start int64
+
+	// End is the offset of the sync marker where reading resumed, or the end of
+	// the stream if no further sync marker was found.
+	End int64
+
+	// Err is the error that was encountered while decoding.
+	Err error
+}
+
+// SkipCorrupt puts the Reader into recovery mode. Normally, Scan stops at the
+// first record or block that can't be decoded, and Err returns the reason. In
+// recovery mode, the Reader instead scans forward to the next sync marker and
+// continues reading records from there. Each skipped section of the input is
+// passed to fn, if it's not nil, and can also be retrieved by calling Skipped.
+//
+// Recovery requires the sync marker to be known, either because the header was
+// read or because a sync marker was already encountered. Otherwise, errors are
+// returned as usual.
+func (r *Reader) SkipCorrupt(fn func(CorruptRange)) {
+	r.recovery = true
+	r.onCorrupt = fn
+}
+
+// Skipped returns the sections of the input that were skipped in recovery
+// mode.
+func (r *Reader) Skipped() []CorruptRange {
+	return r.skipped
+}
+
+// beginRecord marks the start of a record or block, which is where a skipped
+// section starts if the record turns out to be corrupt.
+func (r *Reader) beginRecord() {
+	r.start = r.in.pos
+	r.resyncFrom = r.start + 1
+	if r.recovery {
+		r.in.startRecording()
+	}
+}
+
+// recover handles an error encountered while decoding a record or block. If
+// the Reader isn't in recovery mode, it's closed with the error. Otherwise, the
+// Reader is positioned just after the next sync marker, and recover returns
+// true if there is more data to read.
+func (r *Reader) recover(err error) bool {
+	if !r.recovery || r.syncMarkerBytes == nil {
+		r.close(err)
+		return false
+	}
+
+	r.clear()
+	r.block = blockReader{}
+	skipped := CorruptRange{Start: r.start, Err: err}
+
+	// First, look through what was already read for this record. We start
+	// searching after the first byte (or after the block's own sync marker) so
+	// that we always make progress.
+	r.in.recording = false
+	recorded := r.in.recorded.Bytes()
+	if from := int(r.resyncFrom - r.in.recordedFrom); from >= 0 && from <= len(recorded) {
+		recorded = recorded[from:]
+	} else {
+		recorded = nil
+	}
+
+	if len(recorded) > 0 {
+		if i := bytes.Index(recorded, r.syncMarkerBytes); i >= 0 {
+			rest := recorded[i+SyncSize:]
+			r.in.unread(rest)
+			skipped.End = r.in.pos - SyncSize
+			r.skip(skipped)
+			r.synced = true
+			r.stats.SyncMarkers++
+			return true
+		}
+	}
+
+	// Then, scan forward through the stream, carrying over enough of the
+	// recorded bytes to catch a marker that straddles the two.
+	err = r.scanToSync(recorded)
+	if err == io.EOF {
+		skipped.End = r.in.pos
+		r.skip(skipped)
+		r.closed = true
+		return false
+	} else if err != nil {
+		r.close(err)
+		return false
+	}
+
+	skipped.End = r.in.pos - SyncSize
+	r.skip(skipped)
+	r.synced = true
+	return true
+}
+
+// scanToSync reads forward until just after the next sync marker. The end of
+// prefix, if any, is checked as well, in case a marker starts within it. It
+// returns io.EOF if the end of the stream is reached without finding one.
+func (r *Reader) scanToSync(prefix []byte) error {
+	window := make([]byte, 0, 2*SyncSize)
+	if len(prefix) > SyncSize-1 {
+		prefix = prefix[len(prefix)-(SyncSize-1):]
+	}
+	window = append(window, prefix...)
+
+	for {
+		b, err := r.in.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if len(window) == cap(window) {
+			window = append(window[:0], window[SyncSize:]...)
+		}
+
+		window = append(window, b)
+		if len(window) >= SyncSize && bytes.Equal(window[len(window)-SyncSize:], r.syncMarkerBytes) {
+			r.stats.SyncMarkers++
+			return nil
+		}
+	}
+}
+
+func (r *Reader) skip(skipped CorruptRange) {
+	if skipped.End < skipped.Start {
+		skipped.End = skipped.Start
+	}
+
+	r.skipped = append(r.skipped, skipped)
+	if r.onCorrupt != nil {
+		r.onCorrupt(skipped)
+	}
+}
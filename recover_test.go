@@ -0,0 +1,219 @@
+package sequencefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCorruptible(t *testing.T, cmp compressionSpec, n int) []byte {
+	var pairs []writePair
+	for i := 0; i < n; i++ {
+		pairs = append(pairs, writePair{int64(i), bytes.Repeat([]byte{'x'}, 100)})
+	}
+
+	return assertWrite(t,
+		&WriterConfig{
+			KeyClass:         LongWritableClassName,
+			ValueClass:       BytesWritableClassName,
+			Compression:      cmp.compression,
+			CompressionCodec: cmp.codec,
+			BlockSize:        1000,
+			Rand:             rand.New(rand.NewSource(42)),
+		},
+		pairs,
+	)
+}
+
+func TestSkipCorrupt(t *testing.T) {
+	compressions := []compressionSpec{
+		{NoCompression, 0},
+		{RecordCompression, GzipCompression},
+		{BlockCompression, GzipCompression},
+		{BlockCompression, SnappyCompression},
+	}
+
+	for _, cmp := range compressions {
+		buf := writeCorruptible(t, cmp, 200)
+
+		// Clobber a chunk in the middle of the file.
+		mid := len(buf) / 2
+		for i := mid; i < mid+300; i++ {
+			buf[i] ^= 0xff
+		}
+
+		r := NewReader(bytes.NewReader(buf))
+		require.NoError(t, r.ReadHeader())
+
+		var callbacks []CorruptRange
+		r.SkipCorrupt(func(c CorruptRange) {
+			callbacks = append(callbacks, c)
+		})
+
+		var keys []int64
+		for r.Scan() {
+			keys = append(keys, LongWritable(r.Key()))
+		}
+
+		require.NoError(t, r.Err())
+		require.NotEmpty(t, r.Skipped(), "something should have been skipped")
+		assert.Equal(t, r.Skipped(), callbacks)
+		assert.Less(t, len(keys), 200, "some records should have been lost")
+		assert.Greater(t, len(keys), 100, "records after the corruption should be read")
+		assert.Equal(t, int64(199), keys[len(keys)-1], "the last record should be read")
+
+		for i := 1; i < len(keys); i++ {
+			assert.Less(t, keys[i-1], keys[i], "records should be in order")
+		}
+
+		for _, c := range r.Skipped() {
+			assert.Error(t, c.Err)
+			assert.LessOrEqual(t, c.Start, int64(mid+300))
+			assert.GreaterOrEqual(t, c.End, int64(mid))
+		}
+	}
+}
+
+func TestSkipCorruptTruncated(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{BlockCompression, GzipCompression}, 50)
+	buf = buf[:len(buf)-10]
+
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+	r.SkipCorrupt(nil)
+
+	n := 0
+	for r.Scan() {
+		n++
+	}
+
+	require.NoError(t, r.Err())
+	require.Len(t, r.Skipped(), 1)
+	assert.Equal(t, int64(len(buf)), r.Skipped()[0].End)
+	assert.Less(t, n, 50)
+}
+
+func TestCorruptWithoutRecovery(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{BlockCompression, GzipCompression}, 200)
+	mid := len(buf) / 2
+	for i := mid; i < mid+300; i++ {
+		buf[i] ^= 0xff
+	}
+
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+	for r.Scan() {
+	}
+
+	assert.Error(t, r.Err())
+	assert.Empty(t, r.Skipped())
+}
+
+func TestTruncatedRecord(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{NoCompression, 0}, 10)
+	buf = buf[:len(buf)-10]
+
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+
+	n := 0
+	for r.Scan() {
+		n++
+	}
+
+	assert.Equal(t, 9, n)
+	assert.Equal(t, io.ErrUnexpectedEOF, r.Err())
+}
+
+func TestSkipCorruptLengths(t *testing.T) {
+	compressions := []compressionSpec{
+		{NoCompression, 0},
+		{RecordCompression, GzipCompression},
+		{BlockCompression, GzipCompression},
+	}
+
+	for _, cmp := range compressions {
+		orig := writeCorruptible(t, cmp, 100)
+		r := NewReader(bytes.NewReader(orig))
+		require.NoError(t, r.ReadHeader())
+
+		for off := int(r.Offset()); off < len(orig)-4; off += 97 {
+			// Overwrite four bytes with something that looks like a very long
+			// length, whether it's read as an int or a vint.
+			buf := append([]byte(nil), orig...)
+			copy(buf[off:], []byte{0x8c, 0x7f, 0xff, 0xff})
+
+			// The input size is known for a bytes.Reader, but not for an
+			// arbitrary io.Reader.
+			inputs := []io.Reader{bytes.NewReader(buf), struct{ io.Reader }{bytes.NewReader(buf)}}
+			for _, in := range inputs {
+				r := NewReader(in)
+				require.NoError(t, r.ReadHeader())
+				r.SetMaxLength(64 * 1024)
+				r.SkipCorrupt(nil)
+
+				n := 0
+				for r.Scan() {
+					n++
+				}
+
+				require.NoError(t, r.Err())
+				assert.Greater(t, n, 50, "records around the corruption should be read")
+			}
+		}
+	}
+}
+
+func TestCorruptLengthWithoutRecovery(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{RecordCompression, GzipCompression}, 10)
+
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+	binary.BigEndian.PutUint32(buf[r.Offset():], 0x7fff0000)
+
+	assert.False(t, r.Scan())
+	assert.Equal(t, io.ErrUnexpectedEOF, r.Err())
+
+	r = NewReader(struct{ io.Reader }{bytes.NewReader(buf)})
+	require.NoError(t, r.ReadHeader())
+
+	assert.False(t, r.Scan())
+	assert.ErrorContains(t, r.Err(), "exceeds the maximum")
+}
+
+func TestSkipCorruptByteFlips(t *testing.T) {
+	var compressions []compressionSpec
+	for _, codec := range []CompressionCodec{GzipCompression, SnappyCompression, ZlibCompression, ZstdCompression, Bzip2Compression} {
+		compressions = append(compressions,
+			compressionSpec{RecordCompression, codec}, compressionSpec{BlockCompression, codec})
+	}
+	compressions = append(compressions, compressionSpec{NoCompression, 0})
+
+	rng := rand.New(rand.NewSource(1))
+	for _, cmp := range compressions {
+		orig := writeCorruptible(t, cmp, 100)
+		r := NewReader(bytes.NewReader(orig))
+		require.NoError(t, r.ReadHeader())
+		headerEnd := int(r.Offset())
+
+		for i := 0; i < 200; i++ {
+			buf := append([]byte(nil), orig...)
+			off := headerEnd + rng.Intn(len(buf)-headerEnd)
+			buf[off] ^= byte(1 + rng.Intn(255))
+
+			r := NewReader(bytes.NewReader(buf))
+			require.NoError(t, r.ReadHeader())
+			r.SkipCorrupt(nil)
+
+			assert.NotPanics(t, func() {
+				for r.Scan() {
+				}
+			}, "%s/%s, flipping byte %d", cmp.compression, cmp.codec, off)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package sequencefile
+
+import (
+	"bytes"
+	"io"
+)
+
+// reverseChunkSize is the amount of data read at a time while searching
+// backwards for sync markers.
+const reverseChunkSize = 64 * 1024
+
+var syncEscape = []byte{0xff, 0xff, 0xff, 0xff}
+
+// A ReverseReader reads key/value pairs from a SequenceFile starting at the
+// end and working backwards, which is useful for reading the newest records of
+// log-style files first.
+//
+// The file is split into runs at each sync marker, which for block-compressed
+// files are the blocks themselves. Runs are returned from last to first, and
+// the offsets of sync markers are found by scanning backwards from the end of
+// the file in chunks, as needed. By default, the records within each run are
+// returned in their original, forward order.
+type ReverseReader struct {
+	Header Header
+
+	// ReverseRecords, if set, causes the records within each run to also be
+	// returned from last to first. This requires buffering each run in memory.
+	ReverseRecords bool
+
+	ra        io.ReaderAt
+	size      int64
+	headerEnd int64
+	sync      []byte
+	syncs     []int64
+	end       int64
+
+	run      *Reader
+	runStart int64
+	buf      []byte
+	offsets  []int
+	i        int
+
+	key   []byte
+	value []byte
+	err   error
+}
+
+// NewReverseReader returns a new ReverseReader for the SequenceFile of the
+// given size, read from ra. The header is read immediately.
+func NewReverseReader(ra io.ReaderAt, size int64) (*ReverseReader, error) {
+	r := NewReader(io.NewSectionReader(ra, 0, size))
+	r.size = size
+	err := r.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReverseReader{
+		Header:    r.Header,
+		ra:        ra,
+		size:      size,
+		headerEnd: r.in.pos,
+		sync:      r.syncMarkerBytes,
+		end:       size,
+	}, nil
+}
+
+// Scan advances the reader to the previous run, or the next record within the
+// current run, reading the key and value into memory. These can then be
+// obtained by calling Key and Value. If the start of the file is reached, or
+// there is an error, Scan will return false.
+func (r *ReverseReader) Scan() bool {
+	for r.err == nil {
+		if r.ReverseRecords && r.i > 0 {
+			r.i--
+			r.key, r.value = r.record(r.i)
+			return true
+		} else if !r.ReverseRecords && r.run != nil {
+			if r.run.Scan() {
+				r.key, r.value = r.run.Key(), r.run.Value()
+				return true
+			} else if r.run.Err() != nil {
+				r.err = r.run.Err()
+				return false
+			}
+		}
+
+		if !r.nextRun() {
+			return false
+		}
+	}
+
+	return false
+}
+
+// Key returns the key for the current record. The byte slice will be reused
+// after the next call to Scan.
+func (r *ReverseReader) Key() []byte {
+	return r.key
+}
+
+// Value returns the value for the current record. The byte slice will be
+// reused after the next call to Scan.
+func (r *ReverseReader) Value() []byte {
+	return r.value
+}
+
+// Offset returns the offset in the file of the start of the current run. For
+// block-compressed files, this is the offset of the block containing the
+// current record, and can be passed to Reader.Seek.
+func (r *ReverseReader) Offset() int64 {
+	return r.runStart
+}
+
+// SyncOffsets returns the offsets of the sync markers found so far, from last
+// to first. Each offset is the start of the -1 escape preceding the marker.
+func (r *ReverseReader) SyncOffsets() []int64 {
+	return r.syncs
+}
+
+// Err returns the first error reached while scanning.
+func (r *ReverseReader) Err() error {
+	return r.err
+}
+
+// nextRun sets up a Reader for the run preceding the current one.
+func (r *ReverseReader) nextRun() bool {
+	r.run = nil
+	if r.end <= r.headerEnd {
+		return false
+	}
+
+	start, err := r.prevSync(r.end)
+	if err != nil {
+		r.err = err
+		return false
+	} else if start < 0 {
+		start = r.headerEnd
+	} else {
+		r.syncs = append(r.syncs, start)
+	}
+
+	run := NewReaderCompression(io.NewSectionReader(r.ra, start, r.end-start),
+		r.Header.Compression, r.Header.CompressionCodec)
+	run.Header = r.Header
+	run.syncMarkerBytes = r.sync
+	r.run = run
+	r.runStart = start
+	r.end = start
+
+	if r.ReverseRecords {
+		r.buf = r.buf[:0]
+		r.offsets = r.offsets[:0]
+		for run.Scan() {
+			r.offsets = append(r.offsets, len(r.buf))
+			r.buf = append(r.buf, run.Key()...)
+			r.offsets = append(r.offsets, len(r.buf))
+			r.buf = append(r.buf, run.Value()...)
+		}
+
+		if run.Err() != nil {
+			r.err = run.Err()
+			return false
+		}
+
+		r.offsets = append(r.offsets, len(r.buf))
+		r.i = (len(r.offsets) - 1) / 2
+	}
+
+	return true
+}
+
+// record returns the ith buffered record of the current run.
+func (r *ReverseReader) record(i int) ([]byte, []byte) {
+	k := r.offsets[2*i]
+	v := r.offsets[2*i+1]
+	end := r.offsets[2*i+2]
+	return r.buf[k:v], r.buf[v:end]
+}
+
+// prevSync finds the offset of the last sync escape that starts before end,
+// or returns -1 if there isn't one after the header.
+func (r *ReverseReader) prevSync(end int64) (int64, error) {
+	// Escapes must start in [lo, hi), but to see the whole marker, we need to
+	// read a bit past hi.
+	hi := end
+	for hi > r.headerEnd {
+		lo := hi - reverseChunkSize
+		if lo < r.headerEnd {
+			lo = r.headerEnd
+		}
+
+		readEnd := hi + int64(len(syncEscape)+SyncSize-1)
+		if readEnd > r.size {
+			readEnd = r.size
+		}
+
+		buf := make([]byte, readEnd-lo)
+		n, err := r.ra.ReadAt(buf, lo)
+		if err != nil && !(err == io.EOF && n == len(buf)) {
+			return 0, err
+		}
+
+		for j := len(buf); j > 0; {
+			i := bytes.LastIndex(buf[:j], r.sync)
+			if i < 0 {
+				break
+			}
+
+			escape := lo + int64(i-len(syncEscape))
+			if i >= len(syncEscape) && escape < hi &&
+				bytes.Equal(buf[i-len(syncEscape):i], syncEscape) {
+				return escape, nil
+			}
+
+			// Look for an earlier match, possibly overlapping this one.
+			j = i + SyncSize - 1
+		}
+
+		hi = lo
+	}
+
+	return -1, nil
+}
@@ -0,0 +1,72 @@
+package sequencefile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseReader(t *testing.T) {
+	compressions := []compressionSpec{
+		{NoCompression, 0},
+		{RecordCompression, SnappyCompression},
+		{BlockCompression, GzipCompression},
+	}
+
+	for _, cmp := range compressions {
+		buf := writeCorruptible(t, cmp, 500)
+
+		// Records within runs should come in forward order, with the runs
+		// themselves in reverse.
+		r, err := NewReverseReader(bytes.NewReader(buf), int64(len(buf)))
+		require.NoError(t, err)
+		assert.Equal(t, LongWritableClassName, r.Header.KeyClassName)
+
+		var runs [][]int64
+		lastRun := int64(-1)
+		for r.Scan() {
+			if r.Offset() != lastRun {
+				runs = append(runs, nil)
+				lastRun = r.Offset()
+			}
+
+			runs[len(runs)-1] = append(runs[len(runs)-1], LongWritable(r.Key()))
+		}
+
+		require.NoError(t, r.Err())
+		require.Greater(t, len(runs), 2)
+		if cmp.compression == BlockCompression {
+			// Every block starts with a sync marker, including the first.
+			assert.Len(t, r.SyncOffsets(), len(runs))
+		} else {
+			assert.Len(t, r.SyncOffsets(), len(runs)-1)
+		}
+		assert.Equal(t, int64(0), runs[len(runs)-1][0])
+		assert.Equal(t, int64(499), runs[0][len(runs[0])-1])
+
+		var all []int64
+		for i := len(runs) - 1; i >= 0; i-- {
+			all = append(all, runs[i]...)
+		}
+		for i := range all {
+			require.Equal(t, int64(i), all[i])
+		}
+
+		// With ReverseRecords, everything should come out backwards.
+		r, err = NewReverseReader(bytes.NewReader(buf), int64(len(buf)))
+		require.NoError(t, err)
+		r.ReverseRecords = true
+
+		expected := int64(499)
+		for r.Scan() {
+			require.Equal(t, expected, LongWritable(r.Key()))
+			assert.Len(t, r.Value(), 104)
+			expected--
+		}
+
+		require.NoError(t, r.Err())
+		assert.Equal(t, int64(-1), expected)
+	}
+}
@@ -0,0 +1,195 @@
+package sequencefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/colinmarc/sequencefile/internal/pending"
+)
+
+// A RollingConfig specifies the configuration for a RollingWriter.
+type RollingConfig struct {
+	// WriterConfig is used to create each file. Its Writer field is ignored.
+	WriterConfig WriterConfig
+
+	// Dir is the directory to write files to.
+	Dir string
+
+	// NameFormat is a format string used to name each file, given the index of
+	// the file as an int. It defaults to "part-%05d".
+	NameFormat string
+
+	// StartIndex is the index of the first file.
+	StartIndex int
+
+	// MaxBytes is the size at which a file is finished and a new one started.
+	// For block-compressed files, only completed blocks count towards the size,
+	// so files may exceed it by up to one block.
+	MaxBytes int64
+
+	// MaxRecords is the number of records after which a file is finished and a
+	// new one started.
+	MaxRecords int64
+
+	// MaxAge is the amount of time after which a file is finished and a new one
+	// started. It is only checked when a record is appended, so you should call
+	// Roll periodically if the stream can be idle.
+	MaxAge time.Duration
+
+	// OnFinish, if set, is called with the final path of each file, once it has
+	// been completely written and moved into place. If it returns an error,
+	// that error is returned from the call to Append, Roll or Close that
+	// finished the file.
+	OnFinish func(path string) error
+}
+
+// A RollingWriter writes key/value pairs to a series of SequenceFiles, like
+// part-00000, part-00001 and so on, starting a new file whenever the current
+// one reaches a limit on its size, number of records or age.
+//
+// Each file is written under a temporary name, starting with '.' so that it's
+// ignored by OpenDir and by Hadoop, and moved into place once it is finished.
+// Files are only created once there is a record to write to them, and existing
+// files are never replaced; if a file with the same name already exists, the
+// call to Append that would have created it returns an error.
+type RollingWriter struct {
+	cfg   *RollingConfig
+	index int
+
+	w       *Writer
+	f       *pending.File
+	records int64
+	opened  time.Time
+}
+
+// NewRollingWriter constructs a new RollingWriter.
+func NewRollingWriter(cfg *RollingConfig) (*RollingWriter, error) {
+	c := *cfg
+	if c.NameFormat == "" {
+		c.NameFormat = "part-%05d"
+	}
+
+	info, err := os.Stat(c.Dir)
+	if err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("sequencefile: not a directory: %s", c.Dir)
+	}
+
+	return &RollingWriter{cfg: &c, index: c.StartIndex}, nil
+}
+
+// Append adds a key/value pair to the current file, starting a new file first
+// if necessary. The types of the key and value must match the KeyClass and
+// ValueClass of the WriterConfig.
+func (w *RollingWriter) Append(key interface{}, value interface{}) error {
+	if err := w.prepare(); err != nil {
+		return err
+	}
+
+	if err := w.w.Append(key, value); err != nil {
+		return err
+	}
+
+	w.records++
+	return nil
+}
+
+// AppendRaw adds a serialized key/value pair to the current file, like
+// Writer.AppendRaw, starting a new file first if necessary.
+func (w *RollingWriter) AppendRaw(key, value []byte) error {
+	if err := w.prepare(); err != nil {
+		return err
+	}
+
+	if err := w.w.AppendRaw(key, value); err != nil {
+		return err
+	}
+
+	w.records++
+	return nil
+}
+
+// Roll finishes the current file, if there is one, so that the next record
+// is written to a new file.
+func (w *RollingWriter) Roll() error {
+	if w.w == nil {
+		return nil
+	}
+
+	wr, f := w.w, w.f
+	w.w, w.f = nil, nil
+	if err := wr.Close(); err != nil {
+		f.Abort()
+		return err
+	}
+
+	if err := f.CommitNew(); err != nil {
+		return err
+	}
+
+	if w.cfg.OnFinish != nil {
+		return w.cfg.OnFinish(f.Path())
+	}
+
+	return nil
+}
+
+// Close finishes the current file.
+func (w *RollingWriter) Close() error {
+	return w.Roll()
+}
+
+// prepare rolls the current file if it has reached a limit, and opens a new
+// one if necessary.
+func (w *RollingWriter) prepare() error {
+	if w.w != nil && w.full() {
+		if err := w.Roll(); err != nil {
+			return err
+		}
+	}
+
+	if w.w == nil {
+		return w.open()
+	}
+
+	return nil
+}
+
+func (w *RollingWriter) full() bool {
+	return (w.cfg.MaxBytes > 0 && w.w.Position() >= w.cfg.MaxBytes) ||
+		(w.cfg.MaxRecords > 0 && w.records >= w.cfg.MaxRecords) ||
+		(w.cfg.MaxAge > 0 && time.Since(w.opened) >= w.cfg.MaxAge)
+}
+
+func (w *RollingWriter) open() error {
+	name := fmt.Sprintf(w.cfg.NameFormat, w.index)
+	path := filepath.Join(w.cfg.Dir, name)
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("sequencefile: output file already exists: %s", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := pending.Create(filepath.Join(w.cfg.Dir, "."+name+".tmp"), path)
+	if err != nil {
+		return err
+	}
+
+	cfg := w.cfg.WriterConfig
+	cfg.Writer = f
+	wr, err := NewRawWriter(&cfg)
+	if err != nil {
+		f.Abort()
+		return err
+	}
+
+	w.index++
+	w.w = wr
+	w.f = f
+	w.records = 0
+	w.opened = time.Now()
+	return nil
+}
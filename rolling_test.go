@@ -0,0 +1,141 @@
+package sequencefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingWriterRecords(t *testing.T) {
+	dir := t.TempDir()
+	var finished []string
+	w, err := NewRollingWriter(&RollingConfig{
+		WriterConfig: WriterConfig{
+			KeyClass:   LongWritableClassName,
+			ValueClass: TextClassName,
+		},
+		Dir:        dir,
+		MaxRecords: 30,
+		OnFinish: func(path string) error {
+			// The file should already be in place.
+			_, err := os.Stat(path)
+			assert.NoError(t, err)
+			finished = append(finished, filepath.Base(path))
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.Append(int64(i), "foo"))
+		if i == 45 {
+			// Temporary files shouldn't be picked up while being written.
+			m, err := OpenDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, m.Paths(), 1)
+		}
+	}
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"part-00000", "part-00001", "part-00002", "part-00003"}, finished)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 4, "there should be no temporary files left")
+
+	m, err := OpenDir(dir)
+	require.NoError(t, err)
+
+	n := int64(0)
+	for m.Scan() {
+		require.Equal(t, n, LongWritable(m.Key()))
+		n++
+	}
+
+	require.NoError(t, m.Err())
+	assert.Equal(t, int64(100), n)
+}
+
+func TestRollingWriterBytes(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRollingWriter(&RollingConfig{
+		WriterConfig: WriterConfig{
+			KeyClass:   LongWritableClassName,
+			ValueClass: BytesWritableClassName,
+		},
+		Dir:        dir,
+		NameFormat: "data-%d.seq",
+		StartIndex: 7,
+		MaxBytes:   5000,
+	})
+	require.NoError(t, err)
+
+	value := make([]byte, 100)
+	for i := 0; i < 200; i++ {
+		require.NoError(t, w.AppendRaw(make([]byte, 8), append([]byte{0, 0, 0, 100}, value...)))
+	}
+
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close(), "closing twice should be a no-op")
+
+	matches, err := filepath.Glob(filepath.Join(dir, "data-*.seq"))
+	require.NoError(t, err)
+	assert.Greater(t, len(matches), 3)
+
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Less(t, info.Size(), int64(5200))
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "data-7.seq"))
+	assert.NoError(t, err)
+}
+
+func TestRollingWriterEmpty(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRollingWriter(&RollingConfig{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRollingWriterExisting(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "part-00001")
+	require.NoError(t, os.WriteFile(existing, []byte("earlier run"), 0644))
+
+	cfg := &RollingConfig{
+		WriterConfig: WriterConfig{
+			KeyClass:   LongWritableClassName,
+			ValueClass: TextClassName,
+		},
+		Dir:        dir,
+		MaxRecords: 10,
+	}
+
+	w, err := NewRollingWriter(cfg)
+	require.NoError(t, err)
+
+	var appendErr error
+	for i := 0; i < 20 && appendErr == nil; i++ {
+		appendErr = w.Append(int64(i), "foo")
+	}
+
+	assert.ErrorContains(t, appendErr, "already exists")
+	require.NoError(t, w.Close())
+
+	b, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier run", string(b), "the existing file shouldn't be replaced")
+
+	// The caller's config shouldn't be modified.
+	assert.Empty(t, cfg.NameFormat)
+	assert.Nil(t, cfg.WriterConfig.Rand)
+}
@@ -0,0 +1,182 @@
+package sequencefile
+
+import (
+	"errors"
+	"io"
+)
+
+// SeekKey positions the Reader at the first record with a key greater than
+// or equal to the given one, in a file that is sorted by key, so that the next
+// call to Scan returns it. If key is nil, the Reader is positioned at the first
+// record in the file. To stop at an end key, use SeekRange instead.
+//
+// Keys are serialized, and compared with the RawComparator for the key class.
+// The record is found by binary searching over the sync markers in the file,
+// reading just the first record (or block) after each one, and then scanning
+// forward, so the file must have been written with sync markers, as
+// SequenceFiles normally are. The underlying reader must implement io.Seeker.
+//
+// Seeking the Reader in any other way, or calling Reset, discards the record
+// that SeekKey found.
+func (r *Reader) SeekKey(key []byte) error {
+	cmp, err := NewRawComparator(r.Header.KeyClassName)
+	if err != nil {
+		return err
+	}
+
+	return r.seekKey(key, cmp)
+}
+
+func (r *Reader) seekKey(key []byte, cmp RawComparator) error {
+	if r.syncMarkerBytes == nil {
+		return errors.New("sequencefile: the sync marker is unknown")
+	}
+
+	pos := r.headerEnd
+	if key != nil {
+		var err error
+		pos, err = r.searchSyncs(key, cmp)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+
+	if key != nil {
+		for r.scan() {
+			if cmp(r.key, key) >= 0 {
+				r.unscan()
+				break
+			}
+		}
+
+		if r.err != nil {
+			return r.err
+		}
+	}
+
+	return nil
+}
+
+// A KeyRange iterates over the records in a sorted file with keys in a range,
+// as returned by Reader.SeekRange. It reads from the Reader it was created
+// with, so it's invalidated by seeking or scanning that Reader.
+type KeyRange struct {
+	r    *Reader
+	cmp  RawComparator
+	end  []byte
+	done bool
+}
+
+// SeekRange positions the Reader at the first record with a key greater than
+// or equal to start, like SeekKey, and returns a KeyRange which scans from
+// there up to, but not including, the first record with a key greater than or
+// equal to end. If end is nil, the KeyRange scans to the end of the file.
+func (r *Reader) SeekRange(start, end []byte) (*KeyRange, error) {
+	cmp, err := NewRawComparator(r.Header.KeyClassName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.seekKey(start, cmp); err != nil {
+		return nil, err
+	}
+
+	k := &KeyRange{r: r, cmp: cmp}
+	if end != nil {
+		k.end = append([]byte(nil), end...)
+	}
+
+	return k, nil
+}
+
+// Scan advances to the next record in the range, like Reader.Scan. It returns
+// false once it reaches the end key, the end of the file, or an error. The
+// record with the end key is left for the next call to Reader.Scan.
+func (k *KeyRange) Scan() bool {
+	if k.done {
+		return false
+	}
+
+	if !k.r.Scan() {
+		k.done = true
+		return false
+	}
+
+	if k.end != nil && k.cmp(k.r.Key(), k.end) >= 0 {
+		k.r.unscan()
+		k.done = true
+		return false
+	}
+
+	return true
+}
+
+// Key returns the key of the current record, like Reader.Key.
+func (k *KeyRange) Key() []byte {
+	return k.r.Key()
+}
+
+// Value returns the value of the current record, like Reader.Value.
+func (k *KeyRange) Value() []byte {
+	return k.r.Value()
+}
+
+// Err returns the first error encountered by the Reader, like Reader.Err.
+func (k *KeyRange) Err() error {
+	return k.r.Err()
+}
+
+// searchSyncs finds the offset of the last sync marker followed by a key less
+// than the given one, or the offset of the first record if there isn't one.
+// Scanning forward from there finds the first record with a key greater than
+// or equal to the given one.
+func (r *Reader) searchSyncs(key []byte, cmp RawComparator) (int64, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	// The sync markers found from lo and hi are at best and after, which are
+	// followed by keys less than, and greater than or equal to, the one we're
+	// looking for.
+	lo, hi := r.headerEnd, size
+	best, after := r.headerEnd, size
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		err := r.Sync(mid)
+		if err == io.EOF {
+			hi = mid
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		// Avoid reading the same record twice.
+		s := r.Offset()
+		if s == best {
+			lo = mid
+			continue
+		} else if s >= after {
+			hi = mid
+			continue
+		}
+
+		if !r.scan() {
+			if r.err != nil {
+				return 0, r.err
+			}
+
+			hi, after = mid, s
+		} else if cmp(r.key, key) < 0 {
+			lo, best = mid, s
+		} else {
+			hi, after = mid, s
+		}
+	}
+
+	return best, nil
+}
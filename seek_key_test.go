@@ -0,0 +1,82 @@
+package sequencefile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeekKey(t *testing.T) {
+	for _, compression := range []Compression{NoCompression, RecordCompression, BlockCompression} {
+		t.Run(compression.String(), func(t *testing.T) {
+			path, keys := writeSorted(t, compression)
+			r, err := Open(path)
+			require.NoError(t, err)
+			defer r.Close()
+
+			last := keys[len(keys)-1]
+			ranges := [][2]int64{
+				{-1000, -490}, {-500, -499}, {0, 10}, {17, 18}, {last - 5, last + 1},
+				{last, last + 100}, {last + 1, last + 100}, {100, 100}, {300, 200},
+			}
+
+			for _, rng := range ranges {
+				start, end := rng[0], rng[1]
+				t.Run(fmt.Sprintf("%d-%d", start, end), func(t *testing.T) {
+					var expected []int64
+					for i, k := range keys {
+						if k >= start && k < end {
+							expected = append(expected, int64(i))
+						}
+					}
+
+					k, err := r.SeekRange(longKey(start), longKey(end))
+					require.NoError(t, err)
+					var got []int64
+					for k.Scan() {
+						got = append(got, LongWritable(k.Value()))
+					}
+
+					require.NoError(t, k.Err())
+					assert.Equal(t, expected, got)
+				})
+			}
+		})
+	}
+}
+
+func TestSeekKeyOpenEnded(t *testing.T) {
+	path, keys := writeSorted(t, BlockCompression)
+	r, err := Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.NoError(t, r.SeekKey(longKey(keys[1500])))
+	n := 0
+	for r.Scan() {
+		n++
+	}
+	require.NoError(t, r.Err())
+
+	first := 1500
+	for first > 0 && keys[first-1] == keys[1500] {
+		first--
+	}
+	assert.Equal(t, len(keys)-first, n)
+
+	k, err := r.SeekRange(nil, longKey(-498))
+	require.NoError(t, err)
+	n = 0
+	for k.Scan() {
+		assert.Less(t, LongWritable(k.Key()), int64(-498))
+		n++
+	}
+	require.NoError(t, k.Err())
+	assert.Greater(t, n, 0)
+
+	// The end key only applies to the KeyRange, not the Reader.
+	assert.True(t, r.Scan())
+	assert.Equal(t, int64(-498), LongWritable(r.Key()))
+}
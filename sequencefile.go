@@ -2,7 +2,10 @@
 // SequenceFile format, documented here: http://goo.gl/sOSJmJ
 package sequencefile
 
-import "io"
+import (
+	"fmt"
+	"io"
+)
 
 type Compression int
 type CompressionCodec int
@@ -31,6 +34,36 @@ const (
 	Bzip2Compression
 )
 
+func (c Compression) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case RecordCompression:
+		return "record"
+	case BlockCompression:
+		return "block"
+	default:
+		return fmt.Sprintf("Compression(%d)", int(c))
+	}
+}
+
+func (c CompressionCodec) String() string {
+	switch c {
+	case GzipCompression:
+		return "gzip"
+	case SnappyCompression:
+		return "snappy"
+	case ZlibCompression:
+		return "zlib"
+	case ZstdCompression:
+		return "zstd"
+	case Bzip2Compression:
+		return "bzip2"
+	default:
+		return fmt.Sprintf("CompressionCodec(%d)", int(c))
+	}
+}
+
 type decompressor interface {
 	Read(p []byte) (n int, err error)
 	Reset(r io.Reader) error
@@ -0,0 +1,355 @@
+package sequencefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	defaultSortMemory  = 100 * 1024 * 1024
+	defaultMergeFactor = 100
+)
+
+// sortRecordOverhead is the memory used by each record held by Sort, apart
+// from its key and value: the slice headers, plus what the allocator adds for
+// each of the two copies.
+const sortRecordOverhead = 2*24 + 2*16
+
+// SortOptions specifies how Sort sorts records.
+type SortOptions struct {
+	// Compare, if set, is used to order keys instead of the RawComparator for
+	// the key class of the input.
+	Compare RawComparator
+
+	// MemoryLimit is roughly the number of bytes of records to hold in memory
+	// at once, counting their keys and values and a small overhead for each.
+	// Once it is reached, the records read so far are sorted and spilled to a
+	// temporary file. It defaults to 100MB.
+	MemoryLimit int64
+
+	// MergeFactor is the most runs to merge at once, like io.sort.factor in
+	// Hadoop. If there are more spilled runs than that, they're merged in
+	// several passes, through more temporary files. It defaults to 100, and
+	// can't be less than 2.
+	MergeFactor int
+
+	// TempDir is the directory to write temporary files to. It defaults to
+	// os.TempDir().
+	TempDir string
+}
+
+// Sort reads all the records from the SequenceFiles in src, and writes them
+// to a new SequenceFile, written according to dst, in order by key. The sort
+// is stable, so records with equal keys are written in the order they were
+// read, with the inputs taken in order.
+//
+// Inputs larger than the memory limit in opts are sorted in runs, which are
+// written to temporary files and then merged into the output, in as many
+// passes as the merge factor requires. The temporary files are removed before
+// Sort returns.
+//
+// As with Transcode, the key and value classes are taken from the input, and
+// the metadata too if dst.Metadata is nil. All of the inputs must have the
+// same key and value classes. The Writer is closed once all the records have
+// been written, which also closes dst.Writer if it implements io.Closer. If
+// there's an error, the output is incomplete, and dst.Writer is left open for
+// the caller to discard.
+func Sort(src []io.Reader, dst *WriterConfig, opts *SortOptions) error {
+	if len(src) == 0 {
+		return fmt.Errorf("sequencefile: no files to sort")
+	}
+
+	var o SortOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MemoryLimit <= 0 {
+		o.MemoryLimit = defaultSortMemory
+	}
+	if o.MergeFactor <= 0 {
+		o.MergeFactor = defaultMergeFactor
+	} else if o.MergeFactor < 2 {
+		o.MergeFactor = 2
+	}
+
+	readers, err := readHeaders(src)
+	if err != nil {
+		return err
+	}
+
+	header := readers[0].Header
+	if o.Compare == nil {
+		cmp, err := NewRawComparator(header.KeyClassName)
+		if err != nil {
+			return err
+		}
+		o.Compare = cmp
+	}
+
+	s := &sorter{opts: &o, header: header}
+	defer s.cleanup()
+
+	for _, r := range readers {
+		for r.Scan() {
+			if err := s.add(r.Key(), r.Value()); err != nil {
+				return err
+			}
+		}
+
+		if r.Err() != nil {
+			return r.Err()
+		}
+	}
+
+	w, err := NewRawWriter(copyConfig(dst, header))
+	if err != nil {
+		return err
+	}
+
+	err = s.writeTo(w)
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// A sorter accumulates records into sorted runs, spilling them to temporary
+// files as necessary.
+type sorter struct {
+	opts   *SortOptions
+	header Header
+
+	run  sortRun
+	size int64
+
+	// spills are the runs written to temporary files so far, in order, and
+	// temp is every temporary file created, to be removed by cleanup.
+	spills []string
+	temp   []string
+}
+
+func (s *sorter) add(key, value []byte) error {
+	size := int64(len(key)+len(value)) + sortRecordOverhead
+	if s.size > 0 && s.size+size > s.opts.MemoryLimit {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+
+	s.run.records = append(s.run.records, sortRecord{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	})
+	s.size += size
+	return nil
+}
+
+// spill sorts the current run and writes it to a temporary file.
+func (s *sorter) spill() error {
+	s.sortRun()
+	s.run.i = -1
+	path, err := s.writeTemp(&s.run)
+	if err != nil {
+		return err
+	}
+
+	s.spills = append(s.spills, path)
+	s.run = sortRun{}
+	s.size = 0
+	return nil
+}
+
+// writeTemp writes the records from src to a new temporary file, and returns
+// its path.
+func (s *sorter) writeTemp(src recordSource) (string, error) {
+	f, err := os.CreateTemp(s.opts.TempDir, "sequencefile-sort-")
+	if err != nil {
+		return "", err
+	}
+	s.temp = append(s.temp, f.Name())
+
+	bw := bufio.NewWriter(f)
+	w, err := NewRawWriter(&WriterConfig{
+		Writer:     bw,
+		KeyClass:   s.header.KeyClassName,
+		ValueClass: s.header.ValueClassName,
+	})
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+
+	for src.Scan() {
+		if err := w.AppendRaw(src.Key(), src.Value()); err != nil {
+			f.Close()
+			return "", err
+		}
+	}
+
+	if src.Err() != nil {
+		f.Close()
+		return "", src.Err()
+	}
+
+	if err := w.Close(); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	return f.Name(), f.Close()
+}
+
+func (s *sorter) sortRun() {
+	records := s.run.records
+	sort.SliceStable(records, func(i, j int) bool {
+		return s.opts.Compare(records[i].key, records[j].key) < 0
+	})
+}
+
+// writeTo writes all the records to w, in order, by merging the spilled runs
+// with the one still in memory.
+func (s *sorter) writeTo(w *Writer) error {
+	// Leave room for the run in memory in the final merge.
+	for len(s.spills)+1 > s.opts.MergeFactor {
+		if err := s.mergePass(); err != nil {
+			return err
+		}
+	}
+
+	// The run in memory comes last, since it has the latest records.
+	s.sortRun()
+	s.run.i = -1
+	return s.mergeSpills(s.spills, &s.run, func(m recordSource) error {
+		for m.Scan() {
+			if err := w.AppendRaw(m.Key(), m.Value()); err != nil {
+				return err
+			}
+		}
+
+		return m.Err()
+	})
+}
+
+// mergePass merges each group of MergeFactor consecutive spilled runs into a
+// single run. Since the groups are consecutive, merging them keeps the sort
+// stable.
+func (s *sorter) mergePass() error {
+	var merged []string
+	for start := 0; start < len(s.spills); start += s.opts.MergeFactor {
+		end := start + s.opts.MergeFactor
+		if end > len(s.spills) {
+			end = len(s.spills)
+		}
+
+		group := s.spills[start:end]
+		if len(group) == 1 {
+			merged = append(merged, group[0])
+			continue
+		}
+
+		var path string
+		err := s.mergeSpills(group, nil, func(m recordSource) (err error) {
+			path, err = s.writeTemp(m)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		merged = append(merged, path)
+		for _, p := range group {
+			os.Remove(p)
+		}
+	}
+
+	s.spills = merged
+	return nil
+}
+
+// mergeSpills opens the spilled runs at paths, and calls fn with a merger of
+// them, followed by last if it's not nil.
+func (s *sorter) mergeSpills(paths []string, last recordSource, fn func(recordSource) error) error {
+	var sources []recordSource
+	for _, path := range paths {
+		r, err := Open(path)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		sources = append(sources, r)
+	}
+
+	if last != nil {
+		sources = append(sources, last)
+	}
+
+	return fn(newMerger(s.opts.Compare, sources))
+}
+
+func (s *sorter) cleanup() {
+	for _, path := range s.temp {
+		os.Remove(path)
+	}
+}
+
+type sortRecord struct {
+	key, value []byte
+}
+
+// A sortRun is a run of records held in memory, which can be scanned like a
+// Reader.
+type sortRun struct {
+	records []sortRecord
+	i       int
+}
+
+func (r *sortRun) Scan() bool {
+	if r.i+1 >= len(r.records) {
+		return false
+	}
+
+	r.i++
+	return true
+}
+
+func (r *sortRun) Key() []byte {
+	return r.records[r.i].key
+}
+
+func (r *sortRun) Value() []byte {
+	return r.records[r.i].value
+}
+
+func (r *sortRun) Err() error {
+	return nil
+}
+
+// readHeaders creates a Reader for each input and reads its header, checking
+// that they all have the same key and value classes.
+func readHeaders(src []io.Reader) ([]*Reader, error) {
+	readers := make([]*Reader, len(src))
+	for i, r := range src {
+		readers[i] = NewReader(r)
+		if err := readers[i].ReadHeader(); err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			if err := checkCompatible(readers[0].Header, readers[i].Header); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return readers, nil
+}
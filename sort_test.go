@@ -0,0 +1,160 @@
+package sequencefile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A closeRecorder is a buffer that records whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+// A failingWriter fails once more than limit bytes have been written to it.
+type failingWriter struct {
+	closeRecorder
+	limit int
+}
+
+func (f *failingWriter) Write(b []byte) (int, error) {
+	if f.Len()+len(b) > f.limit {
+		return 0, errors.New("disk full")
+	}
+
+	return f.closeRecorder.Write(b)
+}
+
+func testSort(t *testing.T, opts *SortOptions, less func(a, b int32) bool) {
+	rng := rand.New(rand.NewSource(42))
+	src := []io.Reader{
+		writeUnsorted(t, rng, 500, 0),
+		writeUnsorted(t, rng, 300, 500),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Sort(src, &WriterConfig{Writer: &out}, opts))
+
+	r := NewReader(&out)
+	require.NoError(t, r.ReadHeader())
+	assert.Equal(t, IntWritableClassName, r.Header.KeyClassName)
+	assert.Equal(t, LongWritableClassName, r.Header.ValueClassName)
+	assert.Equal(t, map[string]string{"foo": "bar"}, r.Header.Metadata)
+
+	n := 0
+	var prevKey int32
+	var prevValue int64
+	for r.Scan() {
+		key, value := IntWritable(r.Key()), LongWritable(r.Value())
+		if n > 0 {
+			require.False(t, less(key, prevKey), "keys should be sorted")
+			if key == prevKey {
+				require.Greater(t, value, prevValue, "the sort should be stable")
+			}
+		}
+
+		prevKey, prevValue = key, value
+		n++
+	}
+
+	require.NoError(t, r.Err())
+	assert.Equal(t, 800, n)
+}
+
+func TestSortInMemory(t *testing.T) {
+	testSort(t, nil, func(a, b int32) bool { return a < b })
+}
+
+func TestSortSpills(t *testing.T) {
+	dir := t.TempDir()
+	testSort(t, &SortOptions{MemoryLimit: 1000, TempDir: dir}, func(a, b int32) bool { return a < b })
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "temporary files should be removed")
+}
+
+func TestSortMergePasses(t *testing.T) {
+	for _, factor := range []int{2, 3, 10} {
+		dir := t.TempDir()
+		testSort(t, &SortOptions{MemoryLimit: 1000, MergeFactor: factor, TempDir: dir},
+			func(a, b int32) bool { return a < b })
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "temporary files should be removed")
+	}
+}
+
+func TestSorterMergeFactor(t *testing.T) {
+	opts := &SortOptions{Compare: compareInt, MemoryLimit: 1000, MergeFactor: 3, TempDir: t.TempDir()}
+	s := &sorter{opts: opts, header: Header{KeyClassName: IntWritableClassName, ValueClassName: LongWritableClassName}}
+	defer s.cleanup()
+
+	r := NewReader(writeUnsorted(t, rand.New(rand.NewSource(42)), 500, 0))
+	require.NoError(t, r.ReadHeader())
+	for r.Scan() {
+		require.NoError(t, s.add(r.Key(), r.Value()))
+	}
+	require.NoError(t, r.Err())
+	// Counting the overhead for each record, as well as its 12 bytes of key and
+	// value, there are enough spills for several passes.
+	require.Greater(t, len(s.spills), 3*3)
+
+	var out bytes.Buffer
+	w, err := NewRawWriter(&WriterConfig{Writer: &out, KeyClass: IntWritableClassName, ValueClass: LongWritableClassName})
+	require.NoError(t, err)
+	require.NoError(t, s.writeTo(w))
+	require.NoError(t, w.Close())
+	assert.Less(t, len(s.spills), 3, "the final merge should have at most MergeFactor sources")
+
+	n := 0
+	for r := openBytes(t, out.Bytes()); r.Scan(); {
+		n++
+	}
+	assert.Equal(t, 500, n)
+}
+
+func TestSortCustomComparator(t *testing.T) {
+	reverse := func(a, b []byte) int {
+		return compareInt(b, a)
+	}
+
+	testSort(t, &SortOptions{Compare: reverse, MemoryLimit: 2000, TempDir: t.TempDir()},
+		func(a, b int32) bool { return a > b })
+}
+
+func TestSortFailure(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	src := []io.Reader{writeUnsorted(t, rng, 500, 0)}
+
+	out := &failingWriter{limit: 1000}
+	assert.Error(t, Sort(src, &WriterConfig{Writer: out}, nil))
+	assert.False(t, out.closed, "the output shouldn't be closed after an error")
+}
+
+func TestSortMismatchedClasses(t *testing.T) {
+	var a, b bytes.Buffer
+	wa, err := NewWriter(&WriterConfig{Writer: &a, KeyClass: TextClassName})
+	require.NoError(t, err)
+	require.NoError(t, wa.Close())
+	wb, err := NewWriter(&WriterConfig{Writer: &b, KeyClass: LongWritableClassName})
+	require.NoError(t, err)
+	require.NoError(t, wb.Close())
+
+	var out bytes.Buffer
+	err = Sort([]io.Reader{&a, &b}, &WriterConfig{Writer: &out}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package sequencefile
+
+// ReaderStats holds counters describing the data a Reader has read so far.
+type ReaderStats struct {
+	// Bytes is the number of bytes read from the input stream, including the
+	// header.
+	Bytes int64
+
+	// SyncMarkers is the number of sync markers read.
+	SyncMarkers int64
+
+	// Blocks is the number of blocks decoded. It is always zero unless the file
+	// is block-compressed.
+	Blocks int64
+
+	// CompressedBytes is the total size of the compressed data read, as stored
+	// in the file. For record-compressed files, this is the values; for
+	// block-compressed files, it's all four sections of each block.
+	CompressedBytes int64
+
+	// UncompressedBytes is the total size of the compressed data read, after
+	// decompression.
+	UncompressedBytes int64
+}
+
+// Stats returns counters describing the data read so far.
+func (r *Reader) Stats() ReaderStats {
+	stats := r.stats
+	stats.Bytes = r.in.pos
+	return stats
+}
@@ -0,0 +1,26 @@
+package sequencefile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderStats(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{BlockCompression, GzipCompression}, 200)
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+
+	for r.Scan() {
+	}
+
+	require.NoError(t, r.Err())
+	stats := r.Stats()
+	assert.Equal(t, int64(len(buf)), stats.Bytes)
+	assert.Greater(t, stats.Blocks, int64(1))
+	assert.Equal(t, stats.Blocks, stats.SyncMarkers)
+	assert.Greater(t, stats.UncompressedBytes, int64(200*100))
+	assert.Less(t, stats.CompressedBytes, stats.UncompressedBytes)
+}
@@ -0,0 +1,47 @@
+package sequencefile
+
+// Transcode copies every remaining record from src into a new SequenceFile
+// written according to dst, which can be used to change the compression,
+// codec, block size or metadata of a file.
+//
+// The key and value classes are taken from src.Header, and the keys and values
+// are copied as raw bytes, without being deserialized; KeyClass and
+// ValueClass in dst are ignored. If dst.Metadata is nil, the metadata from
+// src.Header is used instead.
+//
+// The Writer is closed once all the records have been copied, which also
+// closes dst.Writer if it implements io.Closer. If there's an error, the
+// output is incomplete, and dst.Writer is left open for the caller to discard.
+func Transcode(dst *WriterConfig, src *Reader) error {
+	w, err := NewRawWriter(copyConfig(dst, src.Header))
+	if err != nil {
+		return err
+	}
+
+	for src.Scan() {
+		err = w.AppendRaw(src.Key(), src.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	if src.Err() != nil {
+		return src.Err()
+	}
+
+	return w.Close()
+}
+
+// copyConfig returns a copy of dst for writing records copied from a file with
+// the given header, with the same classes and, unless dst specifies its own,
+// the same metadata.
+func copyConfig(dst *WriterConfig, h Header) *WriterConfig {
+	cfg := *dst
+	cfg.KeyClass = h.KeyClassName
+	cfg.ValueClass = h.ValueClassName
+	if cfg.Metadata == nil {
+		cfg.Metadata = h.Metadata
+	}
+
+	return &cfg
+}
@@ -0,0 +1,88 @@
+package sequencefile
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscode(t *testing.T) {
+	compressions := []compressionSpec{
+		{NoCompression, 0},
+		{RecordCompression, ZlibCompression},
+		{BlockCompression, ZstdCompression},
+		{BlockCompression, Bzip2Compression},
+	}
+
+	for _, spec := range files {
+		for _, cmp := range compressions {
+			f, err := os.Open(spec.path)
+			require.NoError(t, err)
+
+			src := NewReader(f)
+			require.NoError(t, src.ReadHeader())
+
+			var buf bytes.Buffer
+			err = Transcode(&WriterConfig{
+				Writer:           &buf,
+				Compression:      cmp.compression,
+				CompressionCodec: cmp.codec,
+			}, src)
+			f.Close()
+			require.NoError(t, err)
+
+			r := NewReader(&buf)
+			require.NoError(t, r.ReadHeader())
+			assert.Equal(t, cmp.compression, r.Header.Compression)
+			assert.Equal(t, cmp.codec, r.Header.CompressionCodec)
+
+			assert.Equal(t, BytesWritableClassName, r.Header.KeyClassName)
+			assert.Equal(t, BytesWritableClassName, r.Header.ValueClassName)
+
+			require.True(t, r.Scan())
+			assert.Equal(t, "Alice", string(BytesWritable(r.Key())))
+			assert.Equal(t, "Practice", string(BytesWritable(r.Value())))
+			require.True(t, r.Scan())
+			assert.Equal(t, "Bob", string(BytesWritable(r.Key())))
+			assert.Equal(t, "Hope", string(BytesWritable(r.Value())))
+			assert.False(t, r.Scan())
+			assert.NoError(t, r.Err())
+		}
+	}
+}
+
+func TestTranscodeUnknownClass(t *testing.T) {
+	var in bytes.Buffer
+	w, err := NewRawWriter(&WriterConfig{
+		Writer:     &in,
+		KeyClass:   "com.example.Custom",
+		ValueClass: TextClassName,
+	})
+	require.NoError(t, err)
+	assert.Error(t, w.Append("foo", "bar"))
+	require.NoError(t, w.AppendRaw([]byte{1, 2, 3, 4}, []byte{3, 'b', 'a', 'r'}))
+	require.NoError(t, w.Close())
+
+	src := NewReader(&in)
+	require.NoError(t, src.ReadHeader())
+
+	var out bytes.Buffer
+	require.NoError(t, Transcode(&WriterConfig{
+		Writer:           &out,
+		Compression:      BlockCompression,
+		CompressionCodec: GzipCompression,
+		Metadata:         map[string]string{"foo": "bar"},
+	}, src))
+
+	r := NewReader(&out)
+	require.NoError(t, r.ReadHeader())
+	assert.Equal(t, "com.example.Custom", r.Header.KeyClassName)
+	assert.Equal(t, map[string]string{"foo": "bar"}, r.Header.Metadata)
+	require.True(t, r.Scan())
+	assert.Equal(t, []byte{1, 2, 3, 4}, r.Key())
+	assert.Equal(t, "bar", Text(r.Value()))
+	assert.False(t, r.Scan())
+}
@@ -0,0 +1,111 @@
+package sequencefile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A Report describes the result of checking the integrity of a SequenceFile
+// with Verify.
+type Report struct {
+	// Header is the header of the file.
+	Header Header
+
+	// Size is the total number of bytes read, including the header.
+	Size int64
+
+	// Records is the number of records that were successfully decoded.
+	Records int64
+
+	// Blocks is the number of blocks that were successfully decoded. It is
+	// always zero unless the file is block-compressed.
+	Blocks int64
+
+	// SyncMarkers is the number of sync markers that matched the one in the
+	// header.
+	SyncMarkers int64
+
+	// Problems lists the sections of the file that couldn't be decoded.
+	Problems []CorruptRange
+
+	// Truncated is true if the file ends in the middle of a record or block.
+	Truncated bool
+}
+
+// OK returns true if no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0 && !r.Truncated
+}
+
+func (r *Report) String() string {
+	if r.OK() {
+		return fmt.Sprintf("ok: %d records, %d blocks, %d sync markers, %d bytes",
+			r.Records, r.Blocks, r.SyncMarkers, r.Size)
+	}
+
+	s := fmt.Sprintf("corrupt: %d records, %d blocks, %d sync markers, %d bytes, %d problems",
+		r.Records, r.Blocks, r.SyncMarkers, r.Size, len(r.Problems))
+	if r.Truncated {
+		s += " (truncated)"
+	}
+
+	return s
+}
+
+// Verify reads an entire SequenceFile from r, checking that the header is
+// valid, every sync marker matches the one in the header, every record and
+// block decompresses, and the lengths in each block match the data. Problems
+// with the data are listed in the returned Report, with their offsets.
+//
+// An error is returned only if the header can't be read, or if reading from r
+// fails for a reason other than reaching the end of the input.
+//
+// Since the size of r isn't necessarily known, a corrupt length is only
+// detected once it exceeds DefaultMaxLength. Use VerifyFile to check a file on
+// disk, where lengths are checked against the size of the file.
+func Verify(r io.Reader) (*Report, error) {
+	sf := NewReader(r)
+	err := sf.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	return verify(sf)
+}
+
+// VerifyFile is like Verify, but opens the file at path with Open.
+func VerifyFile(path string) (*Report, error) {
+	sf, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer sf.Close()
+
+	return verify(sf)
+}
+
+func verify(sf *Reader) (*Report, error) {
+	report := &Report{Header: sf.Header}
+	sf.SkipCorrupt(nil)
+	for sf.Scan() {
+		report.Records++
+	}
+
+	if sf.Err() != nil {
+		return nil, sf.Err()
+	}
+
+	stats := sf.Stats()
+	report.Size = stats.Bytes
+	report.Blocks = stats.Blocks
+	report.SyncMarkers = stats.SyncMarkers
+	report.Problems = sf.Skipped()
+	if n := len(report.Problems); n > 0 {
+		last := report.Problems[n-1]
+		report.Truncated = last.End == report.Size &&
+			(errors.Is(last.Err, io.EOF) || errors.Is(last.Err, io.ErrUnexpectedEOF))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,125 @@
+package sequencefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	for _, spec := range files {
+		t.Run(spec.path, func(t *testing.T) {
+			f, err := os.Open(spec.path)
+			require.NoError(t, err)
+			defer f.Close()
+
+			report, err := Verify(f)
+			require.NoError(t, err)
+			assert.True(t, report.OK())
+			assert.Equal(t, int64(2), report.Records)
+			assert.Equal(t, spec.compression, report.Header.Compression)
+
+			info, err := f.Stat()
+			require.NoError(t, err)
+			assert.Equal(t, info.Size(), report.Size)
+		})
+	}
+}
+
+func TestVerifyCorrupt(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{BlockCompression, GzipCompression}, 200)
+	report, err := Verify(bytes.NewReader(buf))
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	assert.Equal(t, int64(200), report.Records)
+	assert.Equal(t, report.Blocks, report.SyncMarkers)
+
+	mid := len(buf) / 2
+	for i := mid; i < mid+300; i++ {
+		buf[i] ^= 0xff
+	}
+
+	report, err = Verify(bytes.NewReader(buf))
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.False(t, report.Truncated)
+	assert.NotEmpty(t, report.Problems)
+	assert.Less(t, report.Records, int64(200))
+}
+
+func TestVerifyTruncated(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{NoCompression, 0}, 200)
+	report, err := Verify(bytes.NewReader(buf[:len(buf)-5]))
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.True(t, report.Truncated)
+	assert.Equal(t, int64(199), report.Records)
+}
+
+func TestVerifyBadHeader(t *testing.T) {
+	_, err := Verify(bytes.NewReader([]byte("not a sequencefile")))
+	assert.Error(t, err)
+}
+
+func TestVerifyCorruptLength(t *testing.T) {
+	buf := writeCorruptible(t, compressionSpec{RecordCompression, GzipCompression}, 200)
+	r := NewReader(bytes.NewReader(buf))
+	require.NoError(t, r.ReadHeader())
+
+	// Make the first record look like it's nearly 2GB.
+	binary.BigEndian.PutUint32(buf[r.Offset():], 0x7fff0000)
+
+	path := filepath.Join(t.TempDir(), "corrupt.sequencefile")
+	require.NoError(t, os.WriteFile(path, buf, 0644))
+
+	fileReport, err := VerifyFile(path)
+	require.NoError(t, err)
+
+	// An arbitrary io.Reader, with no known size.
+	streamReport, err := Verify(struct{ io.Reader }{bytes.NewReader(buf)})
+	require.NoError(t, err)
+
+	for _, report := range []*Report{fileReport, streamReport} {
+		assert.False(t, report.OK())
+		require.NotEmpty(t, report.Problems)
+		assert.Error(t, report.Problems[0].Err)
+		assert.Equal(t, r.Offset(), report.Problems[0].Start)
+		assert.Greater(t, report.Records, int64(150), "records after the corruption should be read")
+	}
+}
+
+func TestVerifyByteFlips(t *testing.T) {
+	for _, cmp := range []compressionSpec{{BlockCompression, GzipCompression}, {RecordCompression, GzipCompression}} {
+		orig := writeCorruptible(t, cmp, 20)
+		r := NewReader(bytes.NewReader(orig))
+		require.NoError(t, r.ReadHeader())
+		headerEnd := int(r.Offset())
+
+		// Flip every byte in turn, covering the header, the block counts and
+		// lengths, and the compressed data.
+		for off := range orig {
+			buf := append([]byte(nil), orig...)
+			buf[off] ^= 0xff
+
+			var report *Report
+			var err error
+			require.NotPanics(t, func() {
+				report, err = Verify(bytes.NewReader(buf))
+			}, "%s/%s, flipping byte %d", cmp.compression, cmp.codec, off)
+
+			if off < headerEnd && err != nil {
+				continue
+			}
+
+			require.NoError(t, err, "flipping byte %d", off)
+			require.NotNil(t, report)
+			assert.LessOrEqual(t, report.Records, int64(20))
+		}
+	}
+}
@@ -5,13 +5,20 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 )
 
 const (
-	BytesWritableClassName = "org.apache.hadoop.io.BytesWritable"
-	TextClassName          = "org.apache.hadoop.io.Text"
-	IntWritableClassName   = "org.apache.hadoop.io.IntWritable"
-	LongWritableClassName  = "org.apache.hadoop.io.LongWritable"
+	BytesWritableClassName   = "org.apache.hadoop.io.BytesWritable"
+	TextClassName            = "org.apache.hadoop.io.Text"
+	IntWritableClassName     = "org.apache.hadoop.io.IntWritable"
+	LongWritableClassName    = "org.apache.hadoop.io.LongWritable"
+	NullWritableClassName    = "org.apache.hadoop.io.NullWritable"
+	BooleanWritableClassName = "org.apache.hadoop.io.BooleanWritable"
+	FloatWritableClassName   = "org.apache.hadoop.io.FloatWritable"
+	DoubleWritableClassName  = "org.apache.hadoop.io.DoubleWritable"
+	VIntWritableClassName    = "org.apache.hadoop.io.VIntWritable"
+	VLongWritableClassName   = "org.apache.hadoop.io.VLongWritable"
 )
 
 // BytesWritable unwraps a hadoop BytesWritable and returns the actual bytes.
@@ -44,6 +51,37 @@ func LongWritable(b []byte) int64 {
 	return int64(binary.BigEndian.Uint64(b))
 }
 
+// BooleanWritable unwraps a BooleanWritable and returns the deserialized bool.
+func BooleanWritable(b []byte) bool {
+	return b[0] != 0
+}
+
+// FloatWritable unwraps a FloatWritable and returns the deserialized float32.
+func FloatWritable(b []byte) float32 {
+	return math.Float32frombits(binary.BigEndian.Uint32(b))
+}
+
+// DoubleWritable unwraps a DoubleWritable and returns the deserialized
+// float64.
+func DoubleWritable(b []byte) float64 {
+	return math.Float64frombits(binary.BigEndian.Uint64(b))
+}
+
+// VIntWritable unwraps a VIntWritable and returns the deserialized int32.
+func VIntWritable(b []byte) int32 {
+	return int32(VLongWritable(b))
+}
+
+// VLongWritable unwraps a VLongWritable and returns the deserialized int64.
+func VLongWritable(b []byte) int64 {
+	n, err := ReadVInt(bytes.NewReader(b))
+	if err != nil {
+		panic(fmt.Sprintf("sequencefile: unwrapping VLongWritable: %s", err))
+	}
+
+	return n
+}
+
 // A WritableWriter knows how to write data wrapped in Hadoop Writables.
 //
 // Each WritableWriter understands just a single type of data.
@@ -111,6 +149,70 @@ func writeLong(w io.Writer, value interface{}) (err error) {
 	return
 }
 
+func writeNull(w io.Writer, value interface{}) error {
+	if value != nil {
+		return &writableWriteError{NullWritableClassName, "nil", value}
+	}
+
+	return nil
+}
+
+func writeBoolean(w io.Writer, value interface{}) (err error) {
+	v, ok := value.(bool)
+	if !ok {
+		return &writableWriteError{BooleanWritableClassName, "bool", value}
+	}
+
+	var b byte
+	if v {
+		b = 1
+	}
+	_, err = w.Write([]byte{b})
+	return
+}
+
+func writeFloat(w io.Writer, value interface{}) (err error) {
+	v, ok := value.(float32)
+	if !ok {
+		return &writableWriteError{FloatWritableClassName, "float32", value}
+	}
+
+	var bs [4]byte
+	binary.BigEndian.PutUint32(bs[:], math.Float32bits(v))
+	_, err = w.Write(bs[:])
+	return
+}
+
+func writeDouble(w io.Writer, value interface{}) (err error) {
+	v, ok := value.(float64)
+	if !ok {
+		return &writableWriteError{DoubleWritableClassName, "float64", value}
+	}
+
+	var bs [8]byte
+	binary.BigEndian.PutUint64(bs[:], math.Float64bits(v))
+	_, err = w.Write(bs[:])
+	return
+}
+
+func writeVInt(w io.Writer, value interface{}) error {
+	v, ok := value.(int32)
+	if !ok {
+		return &writableWriteError{VIntWritableClassName, "int32", value}
+	}
+
+	return WriteVInt(w, int64(v))
+}
+
+func writeVLong(w io.Writer, value interface{}) error {
+	v, ok := value.(int64)
+	if !ok {
+		return &writableWriteError{VLongWritableClassName, "int64", value}
+	}
+
+	return WriteVInt(w, v)
+}
+
 // NewWritableWriter gets a WritableWriter for a given Hadoop class name.
 func NewWritableWriter(className string) (WritableWriter, error) {
 	switch className {
@@ -122,6 +224,18 @@ func NewWritableWriter(className string) (WritableWriter, error) {
 		return writeInt, nil
 	case LongWritableClassName:
 		return writeLong, nil
+	case NullWritableClassName:
+		return writeNull, nil
+	case BooleanWritableClassName:
+		return writeBoolean, nil
+	case FloatWritableClassName:
+		return writeFloat, nil
+	case DoubleWritableClassName:
+		return writeDouble, nil
+	case VIntWritableClassName:
+		return writeVInt, nil
+	case VLongWritableClassName:
+		return writeVLong, nil
 	default:
 		return nil, fmt.Errorf("Unknown writable class %s", className)
 	}
@@ -87,6 +87,75 @@ func TestLongWritable(t *testing.T) {
 	}
 }
 
+var booleanWritables = []struct {
+	b        []byte
+	expected bool
+}{
+	{[]byte{0x00}, false},
+	{[]byte{0x01}, true},
+}
+
+func TestBooleanWritable(t *testing.T) {
+	for _, spec := range booleanWritables {
+		t.Run(strconv.FormatBool(spec.expected), func(t *testing.T) {
+			assert.Equal(t, spec.expected, BooleanWritable(spec.b), "BooleanWritable should unwrap correctly")
+		})
+	}
+}
+
+var floatWritables = []struct {
+	b        []byte
+	expected float32
+}{
+	{[]byte{0x00, 0x00, 0x00, 0x00}, 0},
+	{[]byte{0x3F, 0xC0, 0x00, 0x00}, 1.5},
+	{[]byte{0xC2, 0x28, 0x00, 0x00}, -42},
+}
+
+func TestFloatWritable(t *testing.T) {
+	for _, spec := range floatWritables {
+		t.Run(strconv.FormatFloat(float64(spec.expected), 'g', -1, 32), func(t *testing.T) {
+			assert.Equal(t, spec.expected, FloatWritable(spec.b), "FloatWritable should unwrap correctly")
+		})
+	}
+}
+
+var doubleWritables = []struct {
+	b        []byte
+	expected float64
+}{
+	{[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 0},
+	{[]byte{0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 1.5},
+	{[]byte{0xC0, 0x45, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, -42},
+}
+
+func TestDoubleWritable(t *testing.T) {
+	for _, spec := range doubleWritables {
+		t.Run(strconv.FormatFloat(spec.expected, 'g', -1, 64), func(t *testing.T) {
+			assert.Equal(t, spec.expected, DoubleWritable(spec.b), "DoubleWritable should unwrap correctly")
+		})
+	}
+}
+
+var vlongWritables = []struct {
+	b        []byte
+	expected int64
+}{
+	{[]byte{0x00}, 0},
+	{[]byte{0x2A}, 42},
+	{[]byte{0x86, 0x03, 0xE7}, -1000},
+	{[]byte{0x8C, 0x7F, 0xFF, 0xFF, 0xFF}, 2147483647},
+}
+
+func TestVLongWritable(t *testing.T) {
+	for _, spec := range vlongWritables {
+		t.Run(strconv.FormatInt(spec.expected, 10), func(t *testing.T) {
+			assert.Equal(t, spec.expected, VLongWritable(spec.b), "VLongWritable should unwrap correctly")
+			assert.Equal(t, int32(spec.expected), VIntWritable(spec.b), "VIntWritable should unwrap correctly")
+		})
+	}
+}
+
 func assertWriteWritable(t *testing.T, className string, v interface{}, expected []byte) {
 	w, err := NewWritableWriter(className)
 	assert.NoError(t, err)
@@ -110,6 +179,20 @@ func TestWriteWritable(t *testing.T) {
 	for _, spec := range longWritables {
 		assertWriteWritable(t, LongWritableClassName, spec.expected, spec.b)
 	}
+	for _, spec := range booleanWritables {
+		assertWriteWritable(t, BooleanWritableClassName, spec.expected, spec.b)
+	}
+	for _, spec := range floatWritables {
+		assertWriteWritable(t, FloatWritableClassName, spec.expected, spec.b)
+	}
+	for _, spec := range doubleWritables {
+		assertWriteWritable(t, DoubleWritableClassName, spec.expected, spec.b)
+	}
+	for _, spec := range vlongWritables {
+		assertWriteWritable(t, VLongWritableClassName, spec.expected, spec.b)
+		assertWriteWritable(t, VIntWritableClassName, int32(spec.expected), spec.b)
+	}
+	assertWriteWritable(t, NullWritableClassName, nil, nil)
 
 	// Errors.
 	var buf bytes.Buffer
@@ -84,6 +84,18 @@ const (
 
 // NewWriter constructs a new Writer.
 func NewWriter(cfg *WriterConfig) (w *Writer, err error) {
+	return newWriter(cfg, false)
+}
+
+// NewRawWriter constructs a new Writer for copying keys and values that are
+// already serialized, using AppendRaw. Unlike NewWriter, it accepts key and
+// value classes that this package doesn't know how to serialize; calling
+// Append with such a class returns an error.
+func NewRawWriter(cfg *WriterConfig) (w *Writer, err error) {
+	return newWriter(cfg, true)
+}
+
+func newWriter(cfg *WriterConfig, raw bool) (w *Writer, err error) {
 	// Set some defaults.
 	if cfg.KeyClass == "" {
 		cfg.KeyClass = BytesWritableClassName
@@ -99,11 +111,11 @@ func NewWriter(cfg *WriterConfig) (w *Writer, err error) {
 	}
 
 	keyWriter, err := NewWritableWriter(cfg.KeyClass)
-	if err != nil {
+	if err != nil && !raw {
 		return nil, err
 	}
 	valueWriter, err := NewWritableWriter(cfg.ValueClass)
-	if err != nil {
+	if err != nil && !raw {
 		return nil, err
 	}
 
@@ -168,6 +180,12 @@ func (w *Writer) writeHeader() error {
 // The types of the key and value must match the KeyClass and ValueClass
 // this Writer was configured with.
 func (w *Writer) Append(key interface{}, value interface{}) (err error) {
+	if w.keyWriter == nil {
+		return fmt.Errorf("Unknown writable class %s", w.cfg.KeyClass)
+	} else if w.valueWriter == nil {
+		return fmt.Errorf("Unknown writable class %s", w.cfg.ValueClass)
+	}
+
 	// These errors do not cause the whole writer to error.
 	var kbuf, vbuf bytes.Buffer
 	if err = w.keyWriter(&kbuf, key); err != nil {
@@ -179,6 +197,22 @@ func (w *Writer) Append(key interface{}, value interface{}) (err error) {
 	return w.pairs.Write(kbuf.Bytes(), vbuf.Bytes())
 }
 
+// AppendRaw adds a key/value pair to this Writer, where the key and value are
+// already serialized, as returned by Reader.Key and Reader.Value. The bytes are
+// written as is, without checking that they match KeyClass and ValueClass.
+func (w *Writer) AppendRaw(key, value []byte) error {
+	return w.pairs.Write(key, value)
+}
+
+// Position returns the number of bytes written so far, including the header.
+// Before a call to Append, this is the offset the record will be written at
+// (possibly preceded by a sync marker) or, for block-compressed files, the
+// offset of the block that will contain it. Either can be passed to
+// Reader.Seek.
+func (w *Writer) Position() int64 {
+	return w.w.pos
+}
+
 // Close frees resources held by this Writer.
 func (w *Writer) Close() error {
 	var ret error
@@ -202,6 +236,8 @@ func (w *Writer) codecName(codec CompressionCodec) (string, error) {
 		return "org.apache.hadoop.io.compress.BZip2Codec", nil
 	case SnappyCompression:
 		return "org.apache.hadoop.io.compress.SnappyCodec", nil
+	case ZlibCompression:
+		return "org.apache.hadoop.io.compress.DefaultCodec", nil
 	case ZstdCompression:
 		return "org.apache.hadoop.io.compress.ZStandardCodec", nil
 	default:
@@ -217,6 +253,8 @@ func (w *Writer) newCompressor(codec CompressionCodec) (compressor, error) {
 		return &bzip2Compressor{}, nil
 	case SnappyCompression:
 		return snappyCompressor{snappyDefaultChunkSize}, nil
+	case ZlibCompression:
+		return &zlibCompressor{}, nil
 	case ZstdCompression:
 		return zstdCompressor{}, nil
 	default:
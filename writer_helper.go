@@ -9,11 +9,12 @@ const syncMarker = -1
 
 // A Writer wrapper that:
 // - Stores any error that occurs, and stops writing.
-// - Keeps track of how many bytes have been written.
+// - Keeps track of how many bytes have been written, in total and since the last sync.
 // - Closes the wrapped writer, if it's close-able.
 type writerHelper struct {
 	w     io.Writer
 	bytes int
+	pos   int64
 	err   error
 }
 
@@ -29,6 +30,7 @@ func (w *writerHelper) Write(buf []byte) (n int, err error) {
 	if w.err == nil {
 		n, w.err = w.w.Write(buf)
 		w.bytes += n
+		w.pos += int64(n)
 	}
 	return n, w.err
 }
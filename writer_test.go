@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"hash/fnv"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"testing"
@@ -98,6 +99,8 @@ func TestWriterRoundTrip(t *testing.T) {
 		{BlockCompression, SnappyCompression},
 		{RecordCompression, ZstdCompression},
 		{BlockCompression, ZstdCompression},
+		{RecordCompression, ZlibCompression},
+		{BlockCompression, ZlibCompression},
 	}
 
 	pairs := []writePair{
@@ -212,3 +215,54 @@ func TestWriterError(t *testing.T) {
 	// Closing should error.
 	assert.Error(t, w.Close())
 }
+
+func TestWriterPosition(t *testing.T) {
+	for _, compression := range []Compression{NoCompression, RecordCompression, BlockCompression} {
+		t.Run(compression.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := &WriterConfig{
+				Writer:     &buf,
+				KeyClass:   LongWritableClassName,
+				ValueClass: TextClassName,
+				BlockSize:  200,
+			}
+			if compression != NoCompression {
+				cfg.Compression = compression
+				cfg.CompressionCodec = GzipCompression
+			}
+
+			w, err := NewWriter(cfg)
+			require.NoError(t, err)
+
+			positions := make([]int64, 300)
+			for i := range positions {
+				positions[i] = w.Position()
+				require.NoError(t, w.Append(int64(i), "some value"))
+			}
+			require.NoError(t, w.Close())
+			assert.Equal(t, int64(buf.Len()), w.Position())
+
+			r := NewReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, r.ReadHeader())
+			for _, i := range []int{299, 0, 150, 151, 42} {
+				_, err := r.Seek(positions[i], io.SeekStart)
+				require.NoError(t, err)
+
+				// For block compression, the position is the start of the block
+				// containing the record.
+				found := false
+				for r.Scan() {
+					if LongWritable(r.Key()) == int64(i) {
+						found = true
+						break
+					} else if compression != BlockCompression {
+						break
+					}
+				}
+
+				require.NoError(t, r.Err())
+				assert.True(t, found, "record %d should be at its position", i)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sequencefile
 
 import (
+	"bytes"
 	"compress/zlib"
 	"io"
 )
@@ -25,3 +26,24 @@ func (z *zlibReaderWrapper) Reset(r io.Reader) error {
 	// implement zlib.Resetter, so this type assertion should be safe.
 	return z.ReadCloser.(zlib.Resetter).Reset(r, nil)
 }
+
+type zlibCompressor struct {
+	zw  *zlib.Writer
+	buf bytes.Buffer
+}
+
+func (z *zlibCompressor) compress(src []byte) ([]byte, error) {
+	if z.zw != nil {
+		z.buf.Reset()
+		z.zw.Reset(&z.buf)
+	} else {
+		z.zw = zlib.NewWriter(&z.buf)
+	}
+	if _, err := z.zw.Write(src); err != nil {
+		return nil, err
+	}
+	if err := z.zw.Close(); err != nil {
+		return nil, err
+	}
+	return z.buf.Bytes(), nil
+}